@@ -0,0 +1,104 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bsl2sonar/finder"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// membershipCmd represents the command for report of subsystems of each metadata object
+var membershipCmd = &cobra.Command{
+	Use:   "membership <srcdir> [parsephrases]",
+	Short: "list all subsystems which contain each metadata object",
+	Long: `membership maps each metadata object to all subsystems that contain it,
+including the nesting chain like "Parent/Child". With parsephrases only objects
+of the resolved scope are listed, and objects shared across different phrases are flagged`,
+	Example: `bsl2sonar membership "/src/cf"
+bsl2sonar membership "/src/cf" "рн_ пс_" --format json`,
+	Args: checkMembershipArgs,
+	Run:  membership,
+}
+
+func init() {
+
+	membershipCmd.Flags().String("format", finder.FormatText, "format of output data: text or json")
+	membershipCmd.Flags().StringP("match", "m", finder.MatchPrefix, "mode of matching parsephrases with subsystems: prefix, glob or regexp")
+	membershipCmd.Flags().BoolP("unicode", "u", false, "transform cyrillic charactes to unicode")
+	membershipCmd.Flags().BoolP("logging", "l", false, "output log info to stdout")
+
+	rootCmd.AddCommand(membershipCmd)
+
+}
+
+// Check membership cmd arguments
+func checkMembershipArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("requires one or two arguments: srcdir [string] and optional parsephrases [string]")
+	}
+	if checkResult, errText := isSrcdirValid(args[0]); !checkResult {
+		return errors.New(errText)
+	}
+	if len(args) == 2 {
+		matchFlag, _ := cmd.Flags().GetString("match")
+		if checkResult, errText := isMatchValid(args[1], matchFlag); !checkResult {
+			return errors.New(errText)
+		}
+	}
+	formatFlag, _ := cmd.Flags().GetString("format")
+	if checkResult, errText := isFormatValid(formatFlag); !checkResult {
+		return errors.New(errText)
+	}
+	return nil
+}
+
+func isFormatValid(formatFlag string) (result bool, errText string) {
+
+	switch formatFlag {
+	case finder.FormatText, finder.FormatJSON:
+		return true, ""
+	}
+
+	errText = fmt.Sprintf("Unknown format \"%s\", use text or json", formatFlag)
+	return false, errText
+}
+
+func membership(cmd *cobra.Command, args []string) {
+
+	var phrases string
+	if len(args) > 1 {
+		phrases = args[1]
+	}
+
+	fndr := sourceFinder(args[0])
+	fndr.SetPhrases(phrases)
+	fndr.Format, _ = cmd.Flags().GetString("format")
+	if finder.IsMachineReadable(fndr.Format) {
+		fndr.Logger.SetOutput(os.Stderr)
+	}
+	fndr.Match, _ = cmd.Flags().GetString("match")
+	fndr.Unicode, _ = cmd.Flags().GetBool("unicode")
+	fndr.Logging, _ = cmd.Flags().GetBool("logging")
+
+	fndr.MembershipToSTDOUT()
+
+}
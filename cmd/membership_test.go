@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipArgs(t *testing.T) {
+	testTable := []struct {
+		stringArgs     []string
+		expectedString string
+	}{
+		{[]string{}, "requires one or two arguments"},
+		{[]string{AbsPathTestSrcFolder, "рн_", "пс_"}, "requires one or two arguments"},
+		{[]string{AbsPathTestFailFolder}, "dosn't exist"},
+		{[]string{AbsPathTestSrcFolder}, ""},
+		{[]string{AbsPathTestSrcFolder, "рн_ пс_"}, ""},
+	}
+
+	for _, testCase := range testTable {
+		err := checkMembershipArgs(membershipCmd, testCase.stringArgs)
+		if len(testCase.expectedString) == 0 {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), testCase.expectedString)
+		}
+	}
+}
+
+func TestIsFormatValid(t *testing.T) {
+	testTable := []struct {
+		formatFlag     string
+		expectedString string
+	}{
+		{"text", ""},
+		{"json", ""},
+		{"xml", "Unknown format"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isFormatValid(testCase.formatFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
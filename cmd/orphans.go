@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// orphansCmd represents the command for report of objects which are not in any subsystem
+var orphansCmd = &cobra.Command{
+	Use:   "orphans <srcdir>",
+	Short: "list metadata objects with bsl modules which are not in any subsystem",
+	Long: `orphans lists every metadata object that has .bsl modules on disk
+but appears in no subsystem's content anywhere in the Subsystems tree.
+Such objects are silently missing from SonarQube analysis by subsystems`,
+	Example: `bsl2sonar orphans "/src/cf"`,
+	Args:    checkOrphansArgs,
+	Run:     orphans,
+}
+
+func init() {
+
+	orphansCmd.Flags().BoolP("unicode", "u", false, "transform cyrillic charactes to unicode")
+	orphansCmd.Flags().BoolP("logging", "l", false, "output log info to stdout")
+
+	rootCmd.AddCommand(orphansCmd)
+
+}
+
+// Check orphans cmd arguments
+func checkOrphansArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("requires only one argument: srcdir [string]")
+	}
+	if checkResult, errText := isSrcdirValid(args[0]); !checkResult {
+		return errors.New(errText)
+	}
+	return nil
+}
+
+func orphans(cmd *cobra.Command, args []string) {
+
+	fndr := sourceFinder(args[0])
+	fndr.Unicode, _ = cmd.Flags().GetBool("unicode")
+	fndr.Logging, _ = cmd.Flags().GetBool("logging")
+
+	fndr.OrphansToSTDOUT()
+
+}
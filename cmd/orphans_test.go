@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphansArgs(t *testing.T) {
+	testTable := []struct {
+		stringArgs     []string
+		expectedString string
+	}{
+		{[]string{}, "requires only one argument"},
+		{[]string{AbsPathTestSrcFolder, "рн_"}, "requires only one argument"},
+		{[]string{AbsPathTestFailFolder}, "dosn't exist"},
+		{[]string{AbsPathTestFailFile}, "is not directory"},
+	}
+
+	for _, testCase := range testTable {
+		err := checkOrphansArgs(orphansCmd, testCase.stringArgs)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), testCase.expectedString)
+		}
+	}
+
+	assert.NoError(t, checkOrphansArgs(orphansCmd, []string{AbsPathTestSrcFolder}))
+}
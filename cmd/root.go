@@ -22,6 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -36,7 +39,17 @@ var rootCmd = &cobra.Command{
 This application is a tool to generate long string with paths to .bsl files and substitute to 
 sonar-properties file`,
 	Example: `bsl2sonar <srcdir> <parsephrases> [flags]
-bsl2sonar "/src/cf" "рн_, рнт_общая" -f "src/sonar-project.properties" -a -u`,
+bsl2sonar "/src/cf" "рн_, рнт_общая" -f "src/sonar-project.properties" -a -u
+bsl2sonar "/src/cf" -o "objects.txt"
+bsl2sonar "dump.tar.gz" "рн_" -a --base "/builds/project/src/cf"
+bsl2sonar "1Cv8.cf" "рн_" -a --extract "/builds/project/src/cf"
+bsl2sonar "/src/cf" "рн_" --format json
+bsl2sonar "/src/cf" "рн_" --format args --exclusions --response-file "sonar-args.properties"
+bsl2sonar "/src/cf" "рн_" --compact -f "src/sonar-project.properties"
+bsl2sonar "/src/cf" "рн_" --exclusions -f "src/sonar-project.properties"
+bsl2sonar "/src/cf" "рн_ пс_" --modules --compact -f "src/sonar-project.properties"
+bsl2sonar "/src/cfe" -e --exclude-adopted
+bsl2sonar "src/cf" "рн_" --prefix "src/cf" --root "src/cfe/Ext1;Расш1_" --root "src/cfe/Ext2;Расш2_;ext/Ext2"`,
 	ValidArgs: []string{"src", "reg"},
 	Args:      checkArgs,
 	Version:   "0.0.1",
@@ -48,48 +61,375 @@ func Execute() {
 	cobra.CheckErr(rootCmd.Execute())
 }
 
+// sources is a finder of the first checked sources, finders of all sources of run are got from it
+var sources *finder.Finder
+
+// sourceFinder returns finder of sources which is made once per run for checking arguments and for command
+func sourceFinder(srcdir string) *finder.Finder {
+	if sources == nil {
+		sources = finder.NewFinder(srcdir, "")
+		return sources
+	}
+	return sources.SourceFinder(srcdir)
+}
+
+// resetSources forgets finders of sources of previous run
+func resetSources() {
+	sources = nil
+}
+
 func init() {
 
+	cobra.OnInitialize(resetSources)
+
 	rootCmd.Flags().StringP("file", "f", "", "absolute path to file sonar-project.properties")
 	rootCmd.Flags().BoolP("absolute", "a", false, "output absolute files path")
 	rootCmd.Flags().BoolP("unicode", "u", false, "transform cyrillic charactes to unicode")
 	rootCmd.Flags().BoolP("generate", "g", false, "generate sonar-project.properties, use only with -f flag")
 	rootCmd.Flags().BoolP("logging", "l", false, "output log info to stdout")
+	rootCmd.Flags().BoolP("recursive", "r", false, "include child subsystems of found subsystems regardless of their names")
+	rootCmd.Flags().StringP("match", "m", finder.MatchPrefix, "mode of matching parsephrases with subsystems: prefix, glob or regexp")
+	rootCmd.Flags().StringP("scope", "s", finder.ScopeSubsystems, "scope of metadata objects selection by parsephrases: subsystems, names or both")
+	rootCmd.Flags().StringP("objects", "o", "", "path to file with list of metadata objects (one per line), \"-\" to read from stdin")
+	rootCmd.Flags().StringSlice("config-modules", []string{}, "include modules of configuration: all or comma separated list of ManagedApplicationModule, OrdinaryApplicationModule, SessionModule, ExternalConnectionModule")
+	rootCmd.Flags().StringSlice("module-kinds", []string{}, "include only modules of kinds: object, manager, form, command, recordset, valuemanager, common")
+	rootCmd.Flags().BoolP("exclude-children", "x", false, "exclude child subsystems of subsystems excluded by \"!prefix\" phrases")
+	rootCmd.Flags().BoolP("extension", "e", false, "select own subsystems and objects of configuration extension, parsephrases default to name prefix of extension")
+	rootCmd.Flags().Bool("exclude-adopted", false, "exclude modules of adopted objects of configuration extension without extension code")
+	rootCmd.Flags().String("prefix", "", "prefix of relative paths to files of srcdir")
+	rootCmd.Flags().String("base", "", "folder to which srcdir archive is considered extracted for absolute paths, defaults to archive path without extension")
+	rootCmd.Flags().StringSlice("ext", finder.DefaultExtensions, "extensions of module files to collect, can be repeated: .bsl, .os")
+	rootCmd.Flags().StringArray("root", []string{}, "additional source root \"srcdir;parsephrases;prefix\", prefix defaults to srcdir, can be repeated")
+	rootCmd.Flags().Bool("compact", false, "collapse paths to globs like \"Catalogs/Name/**/*.bsl\" when all modules of metadata object or type are selected")
+	rootCmd.Flags().Bool("exclusions", false, "output module files of srcdir which are not selected, to $exclusions_line or sonar.exclusions key of file with -f")
+	rootCmd.Flags().Bool("modules", false, "write sonar.modules to file with -f, module for each top level matched subsystem")
+	rootCmd.Flags().String("format", finder.FormatText, "format of output data: text (list of paths), json (modules with objects, kinds and subsystems), args or cmd (sonar-scanner arguments quoted for POSIX shell or cmd.exe), dotenv (SONAR_SCANNER_OPTS variable)")
+	rootCmd.Flags().Int("max-args-length", finder.DefaultMaxArgsLength, "max length of output with --format args, cmd or dotenv, longer lists of paths are written to --response-file")
+	rootCmd.Flags().String("response-file", finder.DefaultResponseFile, "properties file for long lists of paths passed to sonar-scanner by -Dproject.settings")
+	rootCmd.Flags().String("extract", "", "folder to extract sources of srcdir archive or .cf/.cfe file for sonar-scanner, base defaults to it")
 
 }
 
 // Check cmd arguments
 func checkArgs(cmd *cobra.Command, args []string) error {
+	objectsFlag, _ := cmd.Root().Flags().GetString("objects")
+	extensionFlag, _ := cmd.Root().Flags().GetBool("extension")
+	if (len(objectsFlag) != 0 || extensionFlag) && len(args) == 1 {
+		// parsephrases are optional with objects list and in extension mode
+		args = append(args, "")
+	}
 	if len(args) != 2 {
 		return errors.New("requires only two arguments: srcdir [string] and parsephrases [string with comma separate]")
 	}
 	fileFlag, _ := cmd.Root().Flags().GetString("file")
 	genFlag, _ := cmd.Root().Flags().GetBool("generate")
-	checkResult, errText := isArgsValid(args, fileFlag, genFlag)
-	if checkResult {
-		return nil
+	rootsFlag, _ := cmd.Root().Flags().GetStringArray("root")
+	// with additional source roots srcdir can be the main configuration
+	srcdirExtension := extensionFlag && (len(rootsFlag) == 0 || sourceFinder(args[0]).IsExtension())
+	checkResult, errText := isArgsValid(args, fileFlag, genFlag, objectsFlag, srcdirExtension)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	matchFlag, _ := cmd.Root().Flags().GetString("match")
+	checkResult, errText = isMatchValid(args[1], matchFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	scopeFlag, _ := cmd.Root().Flags().GetString("scope")
+	checkResult, errText = isScopeValid(scopeFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	configModulesFlag, _ := cmd.Root().Flags().GetStringSlice("config-modules")
+	checkResult, errText = isConfigModulesValid(configModulesFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	moduleKindsFlag, _ := cmd.Root().Flags().GetStringSlice("module-kinds")
+	checkResult, errText = isModuleKindsValid(moduleKindsFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	checkResult, errText = isRootsValid(rootsFlag, matchFlag, extensionFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	excludeAdoptedFlag, _ := cmd.Root().Flags().GetBool("exclude-adopted")
+	checkResult, errText = isExcludeAdoptedValid(excludeAdoptedFlag, args[0], rootsFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	extFlag, _ := cmd.Root().Flags().GetStringSlice("ext")
+	checkResult, errText = isExtValid(extFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	formatFlag, _ := cmd.Root().Flags().GetString("format")
+	exclusionsFlag, _ := cmd.Root().Flags().GetBool("exclusions")
+	checkResult, errText = isOutputFormatValid(formatFlag, fileFlag, exclusionsFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	modulesFlag, _ := cmd.Root().Flags().GetBool("modules")
+	checkResult, errText = isModulesValid(modulesFlag, fileFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	extractFlag, _ := cmd.Root().Flags().GetString("extract")
+	checkResult, errText = isExtractValid(args[0], extractFlag)
+	if !checkResult {
+		return errors.New(errText)
+	}
+	return nil
+}
+
+func isExtValid(extFlag []string) (result bool, errText string) {
+
+	for _, ext := range extFlag {
+		normalized := finder.NormalizeExt(ext)
+		if len(normalized) < 2 || strings.ContainsAny(normalized[1:], "./\\*?[]") {
+			errText := fmt.Sprintf("Invalid file extension \"%s\"", ext)
+			return false, errText
+		}
+	}
+
+	return true, ""
+}
+
+func isRootsValid(rootsFlag []string, matchFlag string, extensionFlag bool) (result bool, errText string) {
+
+	for _, value := range rootsFlag {
+
+		r, err := finder.ParseRoot(value)
+		if err != nil {
+			errText := fmt.Sprintf("Invalid source root \"%s\": %s", value, err)
+			return false, errText
+		}
+
+		if checkResult, errText := isSrcdirValid(r.Srcdir); !checkResult {
+			return false, errText
+		}
+
+		// parsephrases of extension default to its name prefix
+		if len(r.Phrases) == 0 && extensionFlag && finder.HasObjectBelonging(r.Srcdir) && sourceFinder(r.Srcdir).IsExtension() {
+			continue
+		}
+
+		if len([]rune(r.Phrases)) < 3 {
+			errText := fmt.Sprintf("must be at least 3 characters of parsephrases of source root \"%s\"", r.Srcdir)
+			return false, errText
+		}
+
+		if checkResult, errText := isMatchValid(r.Phrases, matchFlag); !checkResult {
+			return false, errText
+		}
+	}
+
+	return true, ""
+}
+
+func isExcludeAdoptedValid(excludeAdoptedFlag bool, srcdir string, rootsFlag []string) (result bool, errText string) {
+
+	if !excludeAdoptedFlag {
+		return true, ""
+	}
+
+	srcdirs := []string{srcdir}
+	for _, value := range rootsFlag {
+		r, _ := finder.ParseRoot(value)
+		srcdirs = append(srcdirs, r.Srcdir)
+	}
+
+	for _, dir := range srcdirs {
+		if !finder.HasObjectBelonging(dir) {
+			errText := fmt.Sprintf("Can't use flag --exclude-adopted with \"%s\" because adopted objects are not read from .cfe file", dir)
+			return false, errText
+		}
+	}
+
+	return true, ""
+}
+
+func isModuleKindsValid(moduleKindsFlag []string) (result bool, errText string) {
+
+	for _, name := range moduleKindsFlag {
+		if _, ok := finder.ParseModuleKind(name); !ok {
+			errText := fmt.Sprintf("Unknown module kind \"%s\"", name)
+			return false, errText
+		}
+	}
+
+	return true, ""
+}
+
+func isConfigModulesValid(configModulesFlag []string) (result bool, errText string) {
+
+	for _, name := range configModulesFlag {
+		if name == finder.ConfigModulesAll {
+			continue
+		}
+		known := false
+		for _, kind := range finder.ConfigurationModuleKinds {
+			if name == string(kind) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			errText := fmt.Sprintf("Unknown configuration module \"%s\"", name)
+			return false, errText
+		}
+	}
+
+	return true, ""
+}
+
+func isScopeValid(scopeFlag string) (result bool, errText string) {
+
+	switch scopeFlag {
+	case finder.ScopeSubsystems, finder.ScopeNames, finder.ScopeBoth:
+		return true, ""
+	}
+
+	errText = fmt.Sprintf("Unknown scope \"%s\", use subsystems, names or both", scopeFlag)
+	return false, errText
+}
+
+func isMatchValid(phrases string, matchFlag string) (result bool, errText string) {
+
+	// mode is checked even without parsephrases
+	switch matchFlag {
+	case finder.MatchPrefix, finder.MatchGlob, finder.MatchRegexp:
+	default:
+		errText := fmt.Sprintf("Unknown match mode \"%s\", use prefix, glob or regexp", matchFlag)
+		return false, errText
 	}
-	return errors.New(errText)
+
+	for _, phrase := range strings.Fields(phrases) {
+		phrase = strings.TrimPrefix(phrase, "!")
+		// hierarchical paths to subsystems are not patterns
+		if strings.Contains(phrase, "/") {
+			phrase = ""
+		}
+		switch matchFlag {
+		case finder.MatchGlob:
+			if _, err := path.Match(phrase, ""); err != nil {
+				errText := fmt.Sprintf("Invalid glob pattern \"%s\": %s", phrase, err)
+				return false, errText
+			}
+		case finder.MatchRegexp:
+			if _, err := regexp.Compile(phrase); err != nil {
+				errText := fmt.Sprintf("Invalid regular expression \"%s\": %s", phrase, err)
+				return false, errText
+			}
+		}
+	}
+
+	return true, ""
 }
 
-func isArgsValid(args []string, fileFlag string, genFlag bool) (result bool, errText string) {
+func isSrcdirValid(srcdir string) (result bool, errText string) {
 
-	fileInfo, err := os.Stat(args[0])
+	fileInfo, err := os.Stat(srcdir)
 	if os.IsNotExist(err) {
-		errText := fmt.Sprintf("Path \"%s\" dosn't exist", args[0])
+		errText := fmt.Sprintf("Path \"%s\" dosn't exist", srcdir)
+		return false, errText
+	}
+
+	if !fileInfo.IsDir() && !finder.IsArchive(srcdir) {
+		errText := fmt.Sprintf("File \"%s\" is not directory", srcdir)
+		return false, errText
+	}
+
+	if err := sourceFinder(srcdir).CheckSources(); err != nil {
+		return false, err.Error()
+	}
+
+	return true, ""
+}
+
+func isOutputFormatValid(formatFlag string, fileFlag string, exclusionsFlag bool) (result bool, errText string) {
+
+	switch formatFlag {
+	case finder.FormatArgs, finder.FormatCmd, finder.FormatDotenv:
+	default:
+		if checkResult, errText := isFormatValid(formatFlag); !checkResult {
+			errText = fmt.Sprintf("Unknown format \"%s\", use text, json, args, cmd or dotenv", formatFlag)
+			return false, errText
+		}
+	}
+
+	if formatFlag != finder.FormatText && len(fileFlag) != 0 {
+		errText := fmt.Sprintf("Can't use flag --format %s with flag -f because %s is printed to stdout", formatFlag, formatFlag)
+		return false, errText
+	}
+
+	if formatFlag == finder.FormatJSON && exclusionsFlag {
+		errText := "Can't use flag --format json with flag --exclusions"
+		return false, errText
+	}
+
+	return true, ""
+}
+
+func isModulesValid(modulesFlag bool, fileFlag string) (result bool, errText string) {
+
+	if modulesFlag && len(fileFlag) == 0 {
+		errText := "Can't use flag --modules without flag -f because modules are written to sonar-project.properties"
 		return false, errText
 	}
 
-	if !fileInfo.IsDir() {
-		errText := fmt.Sprintf("File \"%s\" is not directory", args[0])
+	return true, ""
+}
+
+func isExtractValid(srcdir string, extractFlag string) (result bool, errText string) {
+
+	if len(extractFlag) != 0 && !finder.IsArchive(srcdir) {
+		errText := fmt.Sprintf("Can't use flag --extract with \"%s\" because it is not an archive or .cf/.cfe file", srcdir)
+		return false, errText
+	}
+
+	return true, ""
+}
+
+func isExtensionValid(srcdir string) (result bool, errText string) {
+
+	if !sourceFinder(srcdir).IsExtension() {
+		errText := fmt.Sprintf("Path \"%s\" is not a dump of configuration extension", srcdir)
 		return false, errText
 	}
 
-	if len([]rune(args[1])) < 3 {
+	return true, ""
+}
+
+func isArgsValid(args []string, fileFlag string, genFlag bool, objectsFlag string, extensionFlag bool) (result bool, errText string) {
+
+	if checkResult, errText := isSrcdirValid(args[0]); !checkResult {
+		return false, errText
+	}
+
+	if extensionFlag {
+		if checkResult, errText := isExtensionValid(args[0]); !checkResult {
+			return false, errText
+		}
+		if len(args[1]) == 0 && len(objectsFlag) == 0 && !finder.HasObjectBelonging(args[0]) {
+			errText := fmt.Sprintf("Name prefix of extension is not read from \"%s\", use parsephrases", args[0])
+			return false, errText
+		}
+	}
+
+	if len([]rune(args[1])) < 3 && ((len(objectsFlag) == 0 && !extensionFlag) || len(args[1]) != 0) {
 		errText := "must be at least 3 characters of parsephrases"
 		return false, errText
 	}
 
+	if len(objectsFlag) != 0 && objectsFlag != "-" {
+		if file, err := os.Stat(objectsFlag); os.IsNotExist(err) || file.IsDir() {
+			errText := fmt.Sprintf("Objects list file \"%s\" not found", objectsFlag)
+			return false, errText
+		}
+	}
+
 	if genFlag {
 		if len(fileFlag) == 0 {
 			errText := "Can't use flag -g without flag -f because need to know path to save template"
@@ -109,12 +449,66 @@ func isArgsValid(args []string, fileFlag string, genFlag bool) (result bool, err
 
 func bsl2sonar(cmd *cobra.Command, args []string) {
 
-	fndr := finder.NewFinder(args[0], args[1])
+	var phrases string
+	if len(args) > 1 {
+		phrases = args[1]
+	}
+
+	fndr := sourceFinder(args[0])
+	fndr.SetPhrases(phrases)
 	fndr.Sfile, _ = cmd.Flags().GetString("file")
 	fndr.Abspath, _ = cmd.Flags().GetBool("absolute")
 	fndr.Unicode, _ = cmd.Flags().GetBool("unicode")
 	fndr.Generate, _ = cmd.Flags().GetBool("generate")
 	fndr.Logging, _ = cmd.Flags().GetBool("logging")
+	fndr.Recursive, _ = cmd.Flags().GetBool("recursive")
+	fndr.ExcludeChildren, _ = cmd.Flags().GetBool("exclude-children")
+	fndr.Match, _ = cmd.Flags().GetString("match")
+	fndr.ObjectsFile, _ = cmd.Flags().GetString("objects")
+	fndr.Scope, _ = cmd.Flags().GetString("scope")
+	fndr.ConfigModules, _ = cmd.Flags().GetStringSlice("config-modules")
+	fndr.Extension, _ = cmd.Flags().GetBool("extension")
+	fndr.ExcludeAdopted, _ = cmd.Flags().GetBool("exclude-adopted")
+	fndr.Prefix, _ = cmd.Flags().GetString("prefix")
+	fndr.Base, _ = cmd.Flags().GetString("base")
+	fndr.Format, _ = cmd.Flags().GetString("format")
+	if finder.IsMachineReadable(fndr.Format) {
+		fndr.Logger.SetOutput(os.Stderr)
+	}
+	fndr.Compact, _ = cmd.Flags().GetBool("compact")
+	fndr.Exclusions, _ = cmd.Flags().GetBool("exclusions")
+	fndr.Modules, _ = cmd.Flags().GetBool("modules")
+	fndr.MaxArgsLength, _ = cmd.Flags().GetInt("max-args-length")
+	fndr.ResponseFile, _ = cmd.Flags().GetString("response-file")
+
+	extractDir, _ := cmd.Flags().GetString("extract")
+	if len(extractDir) != 0 {
+		if len(fndr.Base) == 0 {
+			fndr.Base = extractDir
+		}
+		if err := fndr.ExtractSources(extractDir); err != nil {
+			println(err.Error())
+			return
+		}
+	}
+
+	extensions, _ := cmd.Flags().GetStringSlice("ext")
+	fndr.Extensions = nil
+	for _, ext := range extensions {
+		fndr.Extensions = append(fndr.Extensions, finder.NormalizeExt(ext))
+	}
+
+	roots, _ := cmd.Flags().GetStringArray("root")
+	for _, value := range roots {
+		r, _ := finder.ParseRoot(value)
+		fndr.Roots = append(fndr.Roots, r)
+	}
+
+	moduleKinds, _ := cmd.Flags().GetStringSlice("module-kinds")
+	for _, name := range moduleKinds {
+		kind, _ := finder.ParseModuleKind(name)
+		fndr.ModuleKinds = append(fndr.ModuleKinds, kind)
+	}
 
 	if fndr.Logging {
 		fndr.Logger.Printf(">>> Абсолютный путь к исходным файлам проекта: %s", args[0])
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -12,6 +14,8 @@ var AbsPathTestFailFolder, _ = filepath.Abs("../tests/test_con")
 var AbsPathTestFailFile, _ = filepath.Abs("../tests/fixture-stdout")
 var AbsPathTestNoExistFile, _ = filepath.Abs("../tests/fixture_stdou")
 var AbsPathTemplateSonarFile, _ = filepath.Abs("../tests/template-sonar-project.properties")
+var AbsPathFixtureObjectsListFile, _ = filepath.Abs("../tests/fixture-objects-list")
+var AbsPathTestExtensionFolder, _ = filepath.Abs("../tests/test_cfe")
 
 func TestArgsCount(t *testing.T) {
 	testTable := []struct {
@@ -97,14 +101,315 @@ func TestIsArgsValid(t *testing.T) {
 	}
 
 	for _, testCase := range testTable {
-		_, errText := isArgsValid(testCase.stringArgs, testCase.fileFlag, testCase.genFlag)
+		_, errText := isArgsValid(testCase.stringArgs, testCase.fileFlag, testCase.genFlag, "", false)
 		assert.Contains(t, errText, testCase.expectedString)
 	}
 }
 
 func TestExistingFile(t *testing.T) {
 
-	_, errText := isArgsValid([]string{AbsPathTestSrcFolder, "рн_"}, AbsPathTemplateSonarFile, false)
+	_, errText := isArgsValid([]string{AbsPathTestSrcFolder, "рн_"}, AbsPathTemplateSonarFile, false, "", false)
 	assert.Equal(t, "", errText)
 
 }
+
+func TestIsMatchValid(t *testing.T) {
+	testTable := []struct {
+		phrases        string
+		matchFlag      string
+		expectedString string
+	}{
+		{"рн_ пс_", "prefix", ""},
+		{"рн_* !*устар", "glob", ""},
+		{"[рн_", "glob", "Invalid glob pattern"},
+		{"^рн_ !(устар", "regexp", "Invalid regular expression"},
+		{"рн_", "substring", "Unknown match mode"},
+		{"^рн_ рн_Супер/**", "regexp", ""},
+		{"", "bogus", "Unknown match mode"},
+		{"", "glob", ""},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isMatchValid(testCase.phrases, testCase.matchFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestObjectsList(t *testing.T) {
+	testTable := []struct {
+		stringArgs     []string
+		objectsFlag    string
+		expectedString string
+	}{
+		{
+			[]string{AbsPathTestSrcFolder, ""},
+			AbsPathFixtureObjectsListFile,
+			"",
+		},
+		{
+			[]string{AbsPathTestSrcFolder, ""},
+			"-",
+			"",
+		},
+		{
+			[]string{AbsPathTestSrcFolder, "р"},
+			AbsPathFixtureObjectsListFile,
+			"must be at least 3 characters of parsephrases",
+		},
+		{
+			[]string{AbsPathTestSrcFolder, "рн_"},
+			AbsPathTestNoExistFile,
+			"not found",
+		},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isArgsValid(testCase.stringArgs, "", false, testCase.objectsFlag, false)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsScopeValid(t *testing.T) {
+	testTable := []struct {
+		scopeFlag      string
+		expectedString string
+	}{
+		{"subsystems", ""},
+		{"names", ""},
+		{"both", ""},
+		{"all", "Unknown scope"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isScopeValid(testCase.scopeFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsConfigModulesValid(t *testing.T) {
+	testTable := []struct {
+		configModulesFlag []string
+		expectedString    string
+	}{
+		{[]string{}, ""},
+		{[]string{"all"}, ""},
+		{[]string{"SessionModule", "ManagedApplicationModule"}, ""},
+		{[]string{"ObjectModule"}, "Unknown configuration module"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isConfigModulesValid(testCase.configModulesFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsModuleKindsValid(t *testing.T) {
+	testTable := []struct {
+		moduleKindsFlag []string
+		expectedString  string
+	}{
+		{[]string{}, ""},
+		{[]string{"object", "ManagerModule"}, ""},
+		{[]string{"object", "view"}, "Unknown module kind"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isModuleKindsValid(testCase.moduleKindsFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	testTable := []struct {
+		stringArgs     []string
+		extensionFlag  bool
+		expectedResult bool
+		expectedString string
+	}{
+		{[]string{AbsPathTestExtensionFolder, ""}, true, true, ""},
+		{[]string{AbsPathTestExtensionFolder, "Расш1_"}, true, true, ""},
+		{[]string{AbsPathTestExtensionFolder, "р"}, true, false, "must be at least 3 characters of parsephrases"},
+		{[]string{AbsPathTestExtensionFolder, ""}, false, false, "must be at least 3 characters of parsephrases"},
+		{[]string{AbsPathTestSrcFolder, ""}, true, false, "is not a dump of configuration extension"},
+	}
+
+	for _, testCase := range testTable {
+		result, errText := isArgsValid(testCase.stringArgs, "", false, "", testCase.extensionFlag)
+		assert.Equal(t, testCase.expectedResult, result)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsRootsValid(t *testing.T) {
+	testTable := []struct {
+		rootsFlag      []string
+		extensionFlag  bool
+		expectedString string
+	}{
+		{[]string{}, false, ""},
+		{[]string{AbsPathTestSrcFolder + ";рн_;src/cf"}, false, ""},
+		{[]string{AbsPathTestExtensionFolder}, true, ""},
+		{[]string{AbsPathTestExtensionFolder}, false, "must be at least 3 characters of parsephrases of source root"},
+		{[]string{AbsPathTestSrcFolder}, true, "must be at least 3 characters of parsephrases of source root"},
+		{[]string{AbsPathTestFailFolder + ";рн_"}, false, "dosn't exist"},
+		{[]string{";рн_"}, false, "Invalid source root"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isRootsValid(testCase.rootsFlag, "prefix", testCase.extensionFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestSourceFinder(t *testing.T) {
+
+	resetSources()
+	defer resetSources()
+
+	// sources are opened once for checking arguments and for command
+	fndr := sourceFinder(AbsPathTestSrcFolder)
+	assert.Same(t, fndr, sourceFinder(AbsPathTestSrcFolder))
+	rf := sourceFinder(AbsPathTestExtensionFolder)
+	assert.Same(t, rf, sourceFinder(AbsPathTestExtensionFolder))
+	assert.Same(t, rf, fndr.SourceFinder(AbsPathTestExtensionFolder))
+
+	// the next run opens sources again
+	resetSources()
+	assert.NotSame(t, fndr, sourceFinder(AbsPathTestSrcFolder))
+}
+
+func TestIsExcludeAdoptedValid(t *testing.T) {
+	testTable := []struct {
+		excludeAdoptedFlag bool
+		srcdir             string
+		rootsFlag          []string
+		expectedResult     bool
+	}{
+		{false, "Расш1.cfe", []string{}, true},
+		{true, AbsPathTestExtensionFolder, []string{}, true},
+		{true, "Расш1.cfe", []string{}, false},
+		{true, AbsPathTestSrcFolder, []string{"Расш1.CFE;Расш1_"}, false},
+	}
+
+	for _, testCase := range testTable {
+		result, errText := isExcludeAdoptedValid(testCase.excludeAdoptedFlag, testCase.srcdir, testCase.rootsFlag)
+		assert.Equal(t, testCase.expectedResult, result)
+		if !testCase.expectedResult {
+			assert.Contains(t, errText, "adopted objects are not read from .cfe file")
+		}
+	}
+}
+
+func TestIsExtValid(t *testing.T) {
+	testTable := []struct {
+		extFlag        []string
+		expectedString string
+	}{
+		{[]string{".bsl"}, ""},
+		{[]string{".bsl", "os"}, ""},
+		{[]string{"*.os"}, "Invalid file extension"},
+		{[]string{"."}, "Invalid file extension"},
+		{[]string{"src/.os"}, "Invalid file extension"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isExtValid(testCase.extFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsSrcdirValid(t *testing.T) {
+	testTable := []struct {
+		srcdir         string
+		expectedResult bool
+		expectedString string
+	}{
+		{AbsPathTestSrcFolder, true, ""},
+		{AbsPathTestExtensionFolder, true, ""},
+		{AbsPathTestFailFolder, false, "dosn't exist"},
+		{AbsPathTestFailFile, false, "is not directory"},
+		{filepath.Dir(AbsPathTestSrcFolder), false, "doesn't contain Configuration.xml"},
+	}
+
+	for _, testCase := range testTable {
+		result, errText := isSrcdirValid(testCase.srcdir)
+		assert.Equal(t, testCase.expectedResult, result, testCase.srcdir)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestArchiveSrcdir(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	brokenArchive := filepath.Join(tempDir, "dump.zip")
+	assert.NoError(t, ioutil.WriteFile(brokenArchive, []byte("broken"), os.ModePerm))
+
+	_, errText := isSrcdirValid(brokenArchive)
+	assert.Contains(t, errText, "Can't read archive")
+
+	_, errText = isSrcdirValid(filepath.Join(tempDir, "dump.tar.gz"))
+	assert.Contains(t, errText, "dosn't exist")
+}
+
+func TestIsExtractValid(t *testing.T) {
+	testTable := []struct {
+		srcdir         string
+		extractFlag    string
+		expectedString string
+	}{
+		{AbsPathTestSrcFolder, "", ""},
+		{"1Cv8.cf", "src/cf", ""},
+		{"dump.zip", "src/cf", ""},
+		{AbsPathTestSrcFolder, "src/cf", "Can't use flag --extract"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isExtractValid(testCase.srcdir, testCase.extractFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsOutputFormatValid(t *testing.T) {
+	testTable := []struct {
+		formatFlag     string
+		fileFlag       string
+		exclusionsFlag bool
+		expectedString string
+	}{
+		{"text", "", false, ""},
+		{"json", "", false, ""},
+		{"text", AbsPathTemplateSonarFile, true, ""},
+		{"json", AbsPathTemplateSonarFile, false, "Can't use flag --format json with flag -f"},
+		{"json", "", true, "Can't use flag --format json with flag --exclusions"},
+		{"args", "", true, ""},
+		{"cmd", "", false, ""},
+		{"dotenv", "", true, ""},
+		{"args", AbsPathTemplateSonarFile, false, "Can't use flag --format args with flag -f"},
+		{"xml", "", false, "Unknown format"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isOutputFormatValid(testCase.formatFlag, testCase.fileFlag, testCase.exclusionsFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
+
+func TestIsModulesValid(t *testing.T) {
+	testTable := []struct {
+		modulesFlag    bool
+		fileFlag       string
+		expectedString string
+	}{
+		{false, "", ""},
+		{true, AbsPathTemplateSonarFile, ""},
+		{true, "", "Can't use flag --modules without flag -f"},
+	}
+
+	for _, testCase := range testTable {
+		_, errText := isModulesValid(testCase.modulesFlag, testCase.fileFlag)
+		assert.Contains(t, errText, testCase.expectedString)
+	}
+}
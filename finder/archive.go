@@ -0,0 +1,537 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveExts is a list of extensions of supported archives and 1C containers of configuration and extension
+var archiveExts = []string{".tar.gz", ".tgz", ".tar", ".zip", ".cf", ".cfe"}
+
+// IsArchive is the method for check that path points to supported archive by its extension
+func IsArchive(name string) bool {
+	return len(archiveExt(name)) != 0
+}
+
+// archiveExt returns extension of archive like ".tar.gz" or empty string
+func archiveExt(name string) string {
+	lowerName := strings.ToLower(name)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lowerName, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// ArchiveBase is the method for get default folder to which archive is considered extracted,
+// it is path to archive without extension
+func ArchiveBase(name string) string {
+	return name[:len(name)-len(archiveExt(name))]
+}
+
+// archiveFile is a file or folder inside archive
+type archiveFile struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+	data    []byte
+	zipFile *zip.File
+	inTar   bool // content of file is not kept and is read from tar archive on demand
+}
+
+func (af *archiveFile) Name() string       { return path.Base(af.name) }
+func (af *archiveFile) Size() int64        { return af.size }
+func (af *archiveFile) ModTime() time.Time { return af.modTime }
+func (af *archiveFile) IsDir() bool        { return af.dir }
+func (af *archiveFile) Sys() interface{}   { return nil }
+
+func (af *archiveFile) Mode() fs.FileMode {
+	if af.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// archiveFileSystem is an access to files of sources in archive,
+// files are addressed by paths like "<path to archive>/Catalogs/Справочник1.xml"
+type archiveFileSystem struct {
+	archive  string                  // path to archive
+	root     string                  // folder of sources inside archive
+	files    map[string]*archiveFile // files and folders by paths inside archive
+	children map[string][]string     // sorted names of files in each folder
+}
+
+// openArchive reads list of files of zip or tar archive
+func openArchive(archive string) (*archiveFileSystem, error) {
+
+	afs := &archiveFileSystem{
+		archive:  path.Clean(filepath.ToSlash(archive)),
+		root:     ".",
+		files:    make(map[string]*archiveFile),
+		children: make(map[string][]string),
+	}
+	afs.addFile(&archiveFile{name: ".", dir: true})
+
+	var err error
+	switch archiveExt(archive) {
+	case ".zip":
+		err = afs.readZip(archive)
+	case ".cf", ".cfe":
+		err = afs.readContainerFile(archive)
+	default:
+		err = afs.readTar(archive)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, names := range afs.children {
+		sort.Strings(names)
+	}
+	afs.root = afs.findSourcesRoot()
+
+	return afs, nil
+}
+
+// readZip reads list of files of zip archive, content of files is read on demand
+func (afs *archiveFileSystem) readZip(archive string) error {
+
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		info := zf.FileInfo()
+		afs.addFile(&archiveFile{
+			name:    zf.Name,
+			dir:     info.IsDir(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			zipFile: zf,
+		})
+	}
+
+	return nil
+}
+
+// isReadByFinder checks that content of file is read by finder: descriptions of configuration,
+// subsystems and objects (but not forms and templates) and module files
+func isReadByFinder(name string) bool {
+
+	ext := strings.ToLower(path.Ext(name))
+	if _, ok := languagesByExt[ext]; ok {
+		return true
+	}
+
+	switch ext {
+	case ".mdo":
+		return true
+	case ".xml":
+		for _, dir := range strings.Split(path.Dir(name), "/") {
+			switch dir {
+			case "Ext", "Forms", "Templates":
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// walkTar calls function for each header of tar archive (compressed by gzip or not)
+func walkTar(archive string, fn func(header *tar.Header, reader io.Reader) error) error {
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if archiveExt(archive) != ".tar" {
+		gzr, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzr.Close()
+		reader = gzr
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// readTar reads list of files of tar archive, only content of files read by finder is kept,
+// content of other files is read on demand
+func (afs *archiveFileSystem) readTar(archive string) error {
+	return walkTar(archive, func(header *tar.Header, reader io.Reader) error {
+
+		af := &archiveFile{name: header.Name, size: header.Size, modTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			af.dir = true
+		case tar.TypeReg:
+			if !isReadByFinder(header.Name) {
+				af.inTar = true
+				break
+			}
+			var err error
+			if af.data, err = ioutil.ReadAll(reader); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		afs.addFile(af)
+
+		return nil
+	})
+}
+
+// readTarFiles reads content of files of tar archive by paths inside archive
+func (afs *archiveFileSystem) readTarFiles(names map[string]bool, fn func(name string, data []byte) error) error {
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	left := len(names)
+	err := walkTar(afs.archive, func(header *tar.Header, reader io.Reader) error {
+
+		name := path.Clean(strings.TrimPrefix(filepath.ToSlash(header.Name), "/"))
+		if header.Typeflag != tar.TypeReg || !names[name] {
+			return nil
+		}
+		names[name] = false
+		left--
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		if err = fn(name, data); err != nil {
+			return err
+		}
+		if left == 0 {
+			// the rest of archive is not read
+			return io.EOF
+		}
+
+		return nil
+	})
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
+
+// addFile adds file and all its parent folders to archive file system
+func (afs *archiveFileSystem) addFile(af *archiveFile) {
+
+	af.name = path.Clean(strings.TrimPrefix(filepath.ToSlash(af.name), "/"))
+	if af.name == ".." || strings.HasPrefix(af.name, "../") {
+		return
+	}
+	if _, ok := afs.files[af.name]; ok {
+		return
+	}
+	afs.files[af.name] = af
+
+	if af.name == "." {
+		return
+	}
+
+	dir := path.Dir(af.name)
+	afs.addFile(&archiveFile{name: dir, dir: true, modTime: af.modTime})
+	afs.children[dir] = append(afs.children[dir], path.Base(af.name))
+}
+
+// findSourcesRoot returns the least nested folder with Configuration.xml or 1C:EDT Configuration/Configuration.mdo
+func (afs *archiveFileSystem) findSourcesRoot() string {
+
+	var roots []string
+	for name := range afs.files {
+		switch {
+		case path.Base(name) == "Configuration.xml":
+			roots = append(roots, path.Dir(name))
+		case strings.HasSuffix("/"+name, "/Configuration/Configuration.mdo"):
+			roots = append(roots, path.Dir(path.Dir(name)))
+		}
+	}
+	if len(roots) == 0 {
+		return "."
+	}
+
+	// sources of configuration are in the least nested folder
+	depth := func(root string) int {
+		if root == "." {
+			return -1
+		}
+		return strings.Count(root, "/")
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if depth(roots[i]) != depth(roots[j]) {
+			return depth(roots[i]) < depth(roots[j])
+		}
+		return roots[i] < roots[j]
+	})
+
+	return roots[0]
+}
+
+// innerName returns path inside archive by path to file of sources
+func (afs *archiveFileSystem) innerName(name string) (string, bool) {
+
+	name = path.Clean(filepath.ToSlash(name))
+	if name == afs.archive {
+		return afs.root, true
+	}
+	if !strings.HasPrefix(name, afs.archive+"/") {
+		return "", false
+	}
+
+	return path.Join(afs.root, strings.TrimPrefix(name, afs.archive+"/")), true
+}
+
+// lookup returns file or folder of archive by path to file of sources
+func (afs *archiveFileSystem) lookup(op string, name string) (*archiveFile, error) {
+	innerName, ok := afs.innerName(name)
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	af, ok := afs.files[innerName]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return af, nil
+}
+
+func (afs *archiveFileSystem) ReadFile(name string) ([]byte, error) {
+
+	af, err := afs.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if af.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if af.inTar {
+		var data []byte
+		err = afs.readTarFiles(map[string]bool{af.name: true}, func(_ string, content []byte) error {
+			data = content
+			return nil
+		})
+		return data, err
+	}
+	if af.zipFile == nil {
+		return af.data, nil
+	}
+
+	reader, err := af.zipFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+func (afs *archiveFileSystem) Stat(name string) (fs.FileInfo, error) {
+	af, err := afs.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return af, nil
+}
+
+func (afs *archiveFileSystem) ReadDir(name string) ([]fs.FileInfo, error) {
+
+	af, err := afs.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []fs.FileInfo
+	for _, child := range afs.children[af.name] {
+		infos = append(infos, afs.files[path.Join(af.name, child)])
+	}
+
+	return infos, nil
+}
+
+func (afs *archiveFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+
+	af, err := afs.lookup("lstat", root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	err = afs.walk(root, af, fn)
+	if err == filepath.SkipDir {
+		return nil
+	}
+
+	return err
+}
+
+// walk calls function for file and all descendants of folder in lexical order like filepath.Walk
+func (afs *archiveFileSystem) walk(name string, af *archiveFile, fn filepath.WalkFunc) error {
+
+	if err := fn(name, af, nil); err != nil || !af.dir {
+		return err
+	}
+
+	for _, child := range afs.children[af.name] {
+		childFile := afs.files[path.Join(af.name, child)]
+		err := afs.walk(path.Join(name, child), childFile, fn)
+		if err == filepath.SkipDir && !childFile.dir {
+			return nil
+		}
+		if err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Glob returns paths to files matching pattern, only last element of pattern can contain wildcards
+func (afs *archiveFileSystem) Glob(pattern string) ([]string, error) {
+
+	dir, filePattern := path.Split(filepath.ToSlash(pattern))
+	if _, err := path.Match(filePattern, ""); err != nil {
+		return nil, err
+	}
+
+	af, err := afs.lookup("glob", dir)
+	if err != nil || !af.dir {
+		return nil, nil
+	}
+
+	var matches []string
+	for _, child := range afs.children[af.name] {
+		if matched, _ := path.Match(filePattern, child); matched {
+			matches = append(matches, path.Join(dir, child))
+		}
+	}
+
+	return matches, nil
+}
+
+// extract writes files of sources from archive to folder
+func (afs *archiveFileSystem) extract(dir string) error {
+
+	// files which content is not kept are read from tar archive at once
+	tarFiles := make(map[string]string)
+
+	for name, af := range afs.files {
+
+		relPath := name
+		if afs.root != "." {
+			if !strings.HasPrefix(name, afs.root+"/") {
+				continue
+			}
+			relPath = strings.TrimPrefix(name, afs.root+"/")
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(relPath))
+		if af.dir {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		if af.inTar {
+			tarFiles[name] = target
+			continue
+		}
+
+		data, err := afs.ReadFile(path.Join(afs.archive, relPath))
+		if err != nil {
+			return err
+		}
+		if err = writeExtractedFile(target, data); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range tarFiles {
+		names[name] = true
+	}
+
+	return afs.readTarFiles(names, func(name string, data []byte) error {
+		return writeExtractedFile(tarFiles[name], data)
+	})
+}
+
+// writeExtractedFile writes content of file extracted from archive creating its folder
+func writeExtractedFile(target string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(target, data, 0644)
+}
+
+// ExtractSources is the method for write files of sources from archive or 1C container to folder
+func (f *Finder) ExtractSources(dir string) error {
+
+	afs, ok := f.files.(*archiveFileSystem)
+	if !ok {
+		return fmt.Errorf("Path \"%s\" is not an archive or 1C container", f.srcdir)
+	}
+
+	if err := afs.extract(dir); err != nil {
+		return err
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Исходные файлы извлечены в каталог: %s", dir)
+	}
+
+	return nil
+}
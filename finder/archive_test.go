@@ -0,0 +1,241 @@
+package finder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestArchive packs files of folder to zip or tar archive under prefix folder
+func writeTestArchive(t *testing.T, archive string, srcdir string, prefix string) {
+
+	file, err := os.Create(archive)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+
+	var zw *zip.Writer
+	var tw *tar.Writer
+	if archiveExt(archive) == ".zip" {
+		zw = zip.NewWriter(file)
+		defer zw.Close()
+	} else {
+		gzw := gzip.NewWriter(file)
+		defer gzw.Close()
+		tw = tar.NewWriter(gzw)
+		defer tw.Close()
+	}
+
+	err = filepath.Walk(srcdir, func(wpath string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, _ := filepath.Rel(srcdir, wpath)
+		name := path.Join(prefix, filepath.ToSlash(relPath))
+		data, err := ioutil.ReadFile(wpath)
+		if err != nil {
+			return err
+		}
+		var writer io.Writer
+		if zw != nil {
+			writer, err = zw.Create(name)
+		} else {
+			err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			writer = tw
+		}
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+func TestIsArchive(t *testing.T) {
+	testTable := []struct {
+		name         string
+		expectedOk   bool
+		expectedBase string
+	}{
+		{"dump.zip", true, "dump"},
+		{"builds/dump.tar", true, "builds/dump"},
+		{"builds/dump.tar.gz", true, "builds/dump"},
+		{"dump.TGZ", true, "dump"},
+		{"src/cf", false, "src/cf"},
+		{"dump.gz", false, "dump.gz"},
+		{"builds/1Cv8.cf", true, "builds/1Cv8"},
+		{"builds/1CV8.CF", true, "builds/1CV8"},
+		{"Расш1.CFE", true, "Расш1"},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedOk, IsArchive(testCase.name), testCase.name)
+		assert.Equal(t, testCase.expectedBase, ArchiveBase(testCase.name), testCase.name)
+	}
+}
+
+func TestArchiveFileSystem(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	archive := path.Join(tempDir, "dump.zip")
+	writeTestArchive(t, archive, AbsPathTestEDTFolder, "src")
+
+	afs, err := openArchive(archive)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "src", afs.root)
+
+	// files are addressed by paths under archive
+	data, err := afs.ReadFile(path.Join(archive, "Configuration/SessionModule.bsl"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data)
+
+	_, err = afs.ReadFile(path.Join(archive, "Configuration/Unknown.bsl"))
+	assert.True(t, os.IsNotExist(err))
+
+	info, err := afs.Stat(path.Join(archive, "Catalogs"))
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	infos, err := afs.ReadDir(path.Join(archive, "Catalogs"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(infos))
+
+	matches, err := afs.Glob(path.Join(archive, "Catalogs/Справочник1/*.bsl"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		path.Join(archive, "Catalogs/Справочник1/ManagerModule.bsl"),
+		path.Join(archive, "Catalogs/Справочник1/ObjectModule.bsl"),
+	}, matches)
+
+	var walked []string
+	err = afs.Walk(path.Join(archive, "Documents"), func(wpath string, info fs.FileInfo, err error) error {
+		if info != nil && !info.IsDir() {
+			relPath, _ := filepath.Rel(archive, wpath)
+			walked = append(walked, relPath)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Documents/Документ1/Commands/Команда1/CommandModule.bsl",
+		"Documents/Документ1/ObjectModule.bsl",
+		"Documents/Документ1/Документ1.mdo",
+	}, walked)
+}
+
+func TestTarArchiveContent(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	archive := path.Join(tempDir, "dump.tar.gz")
+	writeTestArchive(t, archive, AbsPathTestSrcFolder, "src")
+
+	afs, err := openArchive(archive)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// only content of files read by finder is kept
+	for name, keep := range map[string]bool{
+		"src/Configuration.xml":                                            true,
+		"src/ConfigDumpInfo.xml":                                           true,
+		"src/Subsystems/рн_Супер.xml":                                      true,
+		"src/Documents/Документ7.xml":                                      true,
+		"src/Documents/Документ7/Ext/ObjectModule.bsl":                     true,
+		"src/Documents/Документ7/Forms/ФормаДокумента.xml":                 false,
+		"src/Documents/Документ7/Forms/ФормаДокумента/Ext/Form.xml":        false,
+		"src/Documents/Документ7/Forms/ФормаДокумента/Ext/Form/Module.bsl": true,
+	} {
+		af, ok := afs.files[name]
+		if assert.True(t, ok, name) {
+			assert.Equal(t, !keep, af.inTar, name)
+			assert.Equal(t, keep, af.data != nil, name)
+		}
+	}
+
+	// content of other files is read on demand
+	formFile := "Documents/Документ7/Forms/ФормаДокумента/Ext/Form.xml"
+	expected, err := ioutil.ReadFile(path.Join(AbsPathTestSrcFolder, formFile))
+	assert.NoError(t, err)
+	data, err := afs.ReadFile(path.Join(archive, formFile))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	extractDir := path.Join(tempDir, "extracted")
+	assert.NoError(t, afs.extract(extractDir))
+	data, err = ioutil.ReadFile(path.Join(extractDir, formFile))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+func TestArchiveBslFilesPaths(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	zipArchive := path.Join(tempDir, "dump.zip")
+	writeTestArchive(t, zipArchive, AbsPathTestSrcFolder, "src/cf")
+	tarArchive := path.Join(tempDir, "dump.tar.gz")
+	writeTestArchive(t, tarArchive, AbsPathTestSrcFolder, "")
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_ !рн_Супер/рн_упс")
+	fndr.ConfigModules = []string{ConfigModulesAll}
+	expectedPaths := fndr.getBslFilesPaths()
+
+	for _, archive := range []string{zipArchive, tarArchive} {
+		fndr := NewFinder(archive, "рн_ !рн_Супер/рн_упс")
+		fndr.ConfigModules = []string{ConfigModulesAll}
+		assert.NoError(t, fndr.CheckSources())
+		assert.Equal(t, LayoutDesigner, fndr.Layout)
+		assert.Equal(t, expectedPaths, fndr.getBslFilesPaths(), archive)
+	}
+
+	// paths to archive are cleaned
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(tempDir))
+	for _, archive := range []string{"./dump.zip", path.Join(tempDir, "..", path.Base(tempDir), "dump.zip")} {
+		fndr := NewFinder(archive, "рн_ !рн_Супер/рн_упс")
+		fndr.ConfigModules = []string{ConfigModulesAll}
+		assert.NoError(t, fndr.CheckSources(), archive)
+		assert.Equal(t, expectedPaths, fndr.getBslFilesPaths(), archive)
+	}
+	assert.NoError(t, os.Chdir(wd))
+
+	// absolute paths are computed as if archive is extracted to base folder
+	fndr = NewFinder(zipArchive, "рн_Супер")
+	fndr.Abspath = true
+	bslFilesPaths := fndr.getBslFilesPaths()
+	assert.Equal(t, path.Join(tempDir, "dump/src/cf/DataProcessors/Обработка10/Ext/ManagerModule.bsl"), bslFilesPaths[0])
+
+	fndr.Base = "/builds/project"
+	bslFilesPaths = fndr.getBslFilesPaths()
+	assert.Equal(t, "/builds/project/src/cf/DataProcessors/Обработка10/Ext/ManagerModule.bsl", bslFilesPaths[0])
+
+	// broken archive
+	brokenArchive := path.Join(tempDir, "broken.zip")
+	assert.NoError(t, ioutil.WriteFile(brokenArchive, []byte("broken"), os.ModePerm))
+	err = NewFinder(brokenArchive, "рн_").CheckSources()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Can't read archive")
+	}
+}
@@ -0,0 +1,581 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+// metadataClassIDs are ids of metadata classes in lists of child objects of configuration in container
+var metadataClassIDs = map[string]string{
+	"37f2fa9a-b276-11d4-9435-004095e12fc7": "Subsystem",
+	"0fe48980-252d-11d6-a3c7-0050bae0a776": "CommonModule",
+	"07ee8426-87f1-11d5-b99c-0050bae0a95d": "CommonForm",
+	"2f1a5187-fb0e-4b05-9489-dc5dd6412348": "CommonCommand",
+	"09736b02-9cac-4e3f-b4f7-d3e9576ab948": "Role",
+	"0195e80c-b157-11d4-9435-004095e12fc7": "Constant",
+	"cf4abea6-37b2-11d4-940f-008048da11f9": "Catalog",
+	"061d872a-5787-460e-95ac-ed74ea3a3e84": "Document",
+	"4612bd75-71b7-4a5c-8cc5-2b0b65f9fa0d": "DocumentJournal",
+	"bc587f20-35d9-11d6-a3c7-0050bae0a776": "Sequence",
+	"f6a80749-5ad7-400b-8519-39dc5dff2542": "Enum",
+	"631b75a0-29e2-11d6-a3c7-0050bae0a776": "Report",
+	"bf845118-327b-4682-b5c6-285d2a0eb296": "DataProcessor",
+	"3e7bfcc0-067d-11d6-a3c7-0050bae0a776": "FilterCriterion",
+	"46b4cd97-fd13-4eaa-aba2-3bddd7699218": "SettingsStorage",
+	"857c4a91-e5f4-4fac-86ec-787626f1c108": "ExchangePlan",
+	"13134201-f60b-11d5-a3c7-0050bae0a776": "InformationRegister",
+	"b64d9a40-1642-11d6-a3c7-0050bae0a776": "AccumulationRegister",
+	"82a1b659-b220-4d94-a9bd-14d757b95a48": "ChartOfCharacteristicTypes",
+	"238e7e88-3c5f-48b2-8a3b-81ebbecb20ed": "ChartOfAccounts",
+	"2deed9b8-0056-4ffe-a473-c20a6c32a0bc": "AccountingRegister",
+	"30b100d6-b29f-47ac-aec7-cb8ca8a54767": "ChartOfCalculationTypes",
+	"f2de87a8-64e5-45eb-a22d-b3aedab050e7": "CalculationRegister",
+	"fcd3404e-1523-48ce-9bc0-ecdb822684a1": "BusinessProcess",
+	"3e63355c-1378-4953-be9b-1deb5fb6bec5": "Task",
+	"8657032e-7740-4e1d-a3ba-5dd6e8afb78f": "WebService",
+	"0fffc09c-8f4c-47cc-b41c-8d5c5a221d79": "HTTPService",
+	"5274d9fc-9c3a-4a71-8f5e-a0db8ab23de5": "ExternalDataSource",
+}
+
+// containerModuleSuffixes are suffixes of names of container elements with modules of metadata object,
+// element with suffix ".0" holds the main module of object, kind of the module depends on metadata class
+var containerModuleSuffixes = []struct {
+	suffix string
+	kinds  []ModuleKind
+}{
+	{".0", []ModuleKind{ObjectModule, RecordSetModule, ValueManagerModule, CommonModule}},
+	{".2", []ModuleKind{ManagerModule}},
+}
+
+// configurationModuleSuffixes are suffixes of names of container elements with modules of configuration
+var configurationModuleSuffixes = []struct {
+	suffix string
+	kind   ModuleKind
+}{
+	{".0", OrdinaryApplicationModule},
+	{".5", ExternalConnectionModule},
+	{".6", ManagedApplicationModule},
+	{".7", SessionModule},
+}
+
+// commandModuleSuffix is a suffix of name of container element with module of command
+const commandModuleSuffix = ".2"
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// classList is a list of child objects of metadata class like {<class id>,2,<id 1>,<id 2>}
+type classList struct {
+	ClassID string
+	IDs     []string
+}
+
+// classLists returns all lists of child objects found in node and its descendants
+func (node *braceNode) classLists() []classList {
+
+	if !node.IsList {
+		return nil
+	}
+
+	if len(node.Items) >= 2 && uuidRegexp.MatchString(node.Items[0].Value) {
+		count, err := strconv.Atoi(node.Items[1].Value)
+		if err == nil && count == len(node.Items)-2 {
+			cl := classList{ClassID: node.Items[0].Value}
+			for _, item := range node.Items[2:] {
+				if !uuidRegexp.MatchString(item.Value) {
+					cl.IDs = nil
+					break
+				}
+				cl.IDs = append(cl.IDs, item.Value)
+			}
+			if len(cl.IDs) == count {
+				return []classList{cl}
+			}
+		}
+	}
+
+	var lists []classList
+	for _, item := range node.Items {
+		lists = append(lists, item.classLists()...)
+	}
+
+	return lists
+}
+
+// values returns all not quoted values of node and its descendants
+func (node *braceNode) values() []string {
+
+	if !node.IsList {
+		if node.IsString {
+			return nil
+		}
+		return []string{node.Value}
+	}
+
+	var values []string
+	for _, item := range node.Items {
+		values = append(values, item.values()...)
+	}
+
+	return values
+}
+
+// objectHeader finds properties of metadata object stored like {0,{0,0,<id>},"Name",{1,"ru","Synonym"},""}
+// and returns name and synonyms of object
+func (node *braceNode) objectHeader(id string) (name string, synonyms []string, ok bool) {
+
+	if !node.IsList {
+		return "", nil, false
+	}
+
+	for idx, item := range node.Items {
+		if !item.IsList || len(item.Items) == 0 || item.Items[len(item.Items)-1].Value != id {
+			continue
+		}
+		if idx+1 >= len(node.Items) || !node.Items[idx+1].IsString {
+			continue
+		}
+		name = node.Items[idx+1].Value
+		// synonyms are pairs of language code and text after count
+		if idx+2 < len(node.Items) && node.Items[idx+2].IsList {
+			synonymItems := node.Items[idx+2].Items
+			for sIdx := 2; sIdx < len(synonymItems); sIdx += 2 {
+				synonyms = append(synonyms, synonymItems[sIdx].Value)
+			}
+		}
+		return name, synonyms, true
+	}
+
+	for _, item := range node.Items {
+		if name, synonyms, ok = item.objectHeader(id); ok {
+			return name, synonyms, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// dumpConfiguration is a structure for marshal Configuration.xml of dump made from container
+type dumpConfiguration struct {
+	XMLName         xml.Name            `xml:"MetaDataObject"`
+	ObjectBelonging string              `xml:"Configuration>Properties>ObjectBelonging,omitempty"`
+	Name            string              `xml:"Configuration>Properties>Name"`
+	Items           []configurationItem `xml:"Configuration>ChildObjects>Item"`
+}
+
+// dumpSynonym is an item of synonym of metadata object
+type dumpSynonym struct {
+	Content string `xml:"content"`
+}
+
+// dumpSubsystem is a structure for marshal subsystem xml file of dump made from container
+type dumpSubsystem struct {
+	XMLName  xml.Name      `xml:"MetaDataObject"`
+	Name     string        `xml:"Subsystem>Properties>Name"`
+	Synonyms []dumpSynonym `xml:"Subsystem>Properties>Synonym>item"`
+	Content  []string      `xml:"Subsystem>Properties>Content>Item"`
+	Children []string      `xml:"Subsystem>ChildObjects>Subsystem"`
+}
+
+// containerObject is a top level metadata object of configuration in container
+type containerObject struct {
+	ID       string
+	Type     *MetadataType
+	Name     string
+	Synonyms []string
+}
+
+// containerDump makes hierarchical dump of Designer from elements of 1C container
+type containerDump struct {
+	afs       *archiveFileSystem
+	container *container
+	extension bool // container is a file of configuration extension
+	objects   map[string]*containerObject
+}
+
+// HasObjectBelonging is the method for check that belonging of objects of extension and its name prefix
+// can be read from sources, they are not read from .cfe file
+func HasObjectBelonging(srcdir string) bool {
+	return archiveExt(srcdir) != ".cfe"
+}
+
+// readContainerFile reads .cf or .cfe file and adds files of its dump to archive file system,
+// only elements of container used by dump are read and inflated
+func (afs *archiveFileSystem) readContainerFile(archive string) error {
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	c, err := openContainer(file, int(info.Size()), true)
+	if err != nil {
+		return err
+	}
+
+	cd := &containerDump{
+		afs:       afs,
+		container: c,
+		extension: archiveExt(archive) == ".cfe",
+		objects:   make(map[string]*containerObject),
+	}
+
+	return cd.dump()
+}
+
+// tree parses element of container in 1C internal format
+func (cd *containerDump) tree(name string) (*braceNode, error) {
+	data, ok, err := cd.container.element(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("element %s is not found in container", name)
+	}
+	return parseBraces(string(data))
+}
+
+// moduleText returns text of module from element of container, element is either text
+// or nested container with the text in part like "text" or "module" (for ordinary forms)
+func (cd *containerDump) moduleText(name string, part string) ([]byte, bool, error) {
+
+	data, ok, err := cd.container.element(name)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return nestedModuleText(data, part)
+}
+
+// nestedModuleText returns text of module from data of element which is either text or nested container
+func nestedModuleText(data []byte, part string) ([]byte, bool, error) {
+
+	if !isContainer(data) {
+		return data, part == "text", nil
+	}
+
+	elements, err := readContainer(data, false)
+	if err != nil {
+		return nil, false, nil
+	}
+	for _, element := range elements {
+		if element.Name == part {
+			return element.Data, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// formModuleText returns text of module of form: ordinary form is a nested container with part "module",
+// managed form is a text in internal format with module stored as the first quoted value of the root list
+func (cd *containerDump) formModuleText(id string) ([]byte, bool, error) {
+
+	data, ok, err := cd.container.element(id + ".0")
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if isContainer(data) {
+		return nestedModuleText(data, "module")
+	}
+
+	// description of managed form starts with version, templates in internal format start with quoted values
+	formTree, err := parseBraces(string(data))
+	if err != nil || !formTree.IsList || len(formTree.Items) == 0 || formTree.Items[0].IsList || formTree.Items[0].IsString {
+		return nil, false, nil
+	}
+	for _, item := range formTree.Items {
+		if item.IsString {
+			return []byte(item.Value), true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// addFile adds file of dump by path relative to sources root
+func (cd *containerDump) addFile(relPath string, data []byte) {
+	cd.afs.addFile(&archiveFile{name: relPath, size: int64(len(data)), data: data})
+}
+
+// addXMLFile adds xml file of dump by path relative to sources root
+func (cd *containerDump) addXMLFile(relPath string, value interface{}) error {
+	data, err := xml.MarshalIndent(value, "", "\t")
+	if err != nil {
+		return err
+	}
+	cd.addFile(relPath, append([]byte(xml.Header), data...))
+	return nil
+}
+
+// dump reads configuration from container and adds Configuration.xml, subsystems and modules to file system
+func (cd *containerDump) dump() error {
+
+	// root element is like {2,<id of configuration>,}
+	root, err := cd.tree("root")
+	if err != nil {
+		return err
+	}
+	if len(root.Items) < 2 {
+		return fmt.Errorf("invalid root element of container")
+	}
+	configurationID := root.Items[1].Value
+
+	configurationTree, err := cd.tree(configurationID)
+	if err != nil {
+		return err
+	}
+
+	dc := &dumpConfiguration{}
+	dc.Name, _, _ = configurationTree.objectHeader(configurationID)
+	if cd.extension {
+		// file of extension is an extension itself, belonging of its objects is not read
+		dc.ObjectBelonging = objectBelongingAdopted
+	}
+
+	var subsystems []*containerObject
+
+	for _, cl := range configurationTree.classLists() {
+
+		typeName, ok := metadataClassIDs[cl.ClassID]
+		if !ok {
+			continue
+		}
+		mt, _ := LookupMetadataType(typeName)
+
+		for _, id := range cl.IDs {
+
+			co, err := cd.readObject(id, mt)
+			if err != nil {
+				return err
+			}
+			dc.Items = append(dc.Items, configurationItem{XMLName: xml.Name{Local: mt.Name}, Name: co.Name})
+
+			if mt.Name == "Subsystem" {
+				subsystems = append(subsystems, co)
+				continue
+			}
+			cd.objects[id] = co
+			if err = cd.dumpModules(co); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = cd.addXMLFile("Configuration.xml", dc); err != nil {
+		return err
+	}
+
+	for _, cm := range configurationModuleSuffixes {
+		text, ok, err := cd.moduleText(configurationID+cm.suffix, "text")
+		if err != nil {
+			return err
+		}
+		if ok {
+			cd.addFile(path.Join("Ext", string(cm.kind)+".bsl"), text)
+		}
+	}
+
+	for _, co := range subsystems {
+		if err = cd.dumpSubsystem("Subsystems", co); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readObject reads name and synonyms of metadata object from its element
+func (cd *containerDump) readObject(id string, mt *MetadataType) (*containerObject, error) {
+
+	tree, err := cd.tree(id)
+	if err != nil {
+		return nil, err
+	}
+
+	co := &containerObject{ID: id, Type: mt}
+	var ok bool
+	if co.Name, co.Synonyms, ok = tree.objectHeader(id); !ok {
+		return nil, fmt.Errorf("name of metadata object %s is not found in container", id)
+	}
+
+	return co, nil
+}
+
+// dumpModules adds modules of metadata object, of its forms and commands to file system
+func (cd *containerDump) dumpModules(co *containerObject) error {
+
+	objectDir := path.Join(co.Type.Dir, co.Name)
+
+	switch co.Type.Name {
+	case "CommonForm":
+		text, ok, err := cd.formModuleText(co.ID)
+		if ok {
+			cd.addFile(path.Join(objectDir, "Ext", "Form", "Module.bsl"), text)
+		}
+		return err
+	case "CommonCommand":
+		text, ok, err := cd.moduleText(co.ID+commandModuleSuffix, "text")
+		if ok {
+			cd.addFile(path.Join(objectDir, "Ext", string(CommandModule)+".bsl"), text)
+		}
+		return err
+	}
+
+	for _, ms := range containerModuleSuffixes {
+		for _, kind := range ms.kinds {
+			if !co.Type.HasModuleKind(kind) {
+				continue
+			}
+			text, ok, err := cd.moduleText(co.ID+ms.suffix, "text")
+			if err != nil {
+				return err
+			}
+			if ok {
+				cd.addFile(path.Join(objectDir, "Ext", string(kind)+".bsl"), text)
+			}
+			break
+		}
+	}
+
+	if !co.Type.HasModuleKind(FormModule) && !co.Type.HasModuleKind(CommandModule) {
+		return nil
+	}
+
+	tree, err := cd.tree(co.ID)
+	if err != nil {
+		return err
+	}
+
+	// commands are described in element of object and hold module in own element
+	if co.Type.HasModuleKind(CommandModule) {
+		added := make(map[string]bool)
+		for _, id := range tree.values() {
+			if id == co.ID || added[id] || !uuidRegexp.MatchString(id) {
+				continue
+			}
+			text, ok, err := cd.moduleText(id+commandModuleSuffix, "text")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			commandName, _, ok := tree.objectHeader(id)
+			if !ok {
+				continue
+			}
+			added[id] = true
+			cd.addFile(path.Join(objectDir, "Commands", commandName, "Ext", string(CommandModule)+".bsl"), text)
+		}
+	}
+
+	if !co.Type.HasModuleKind(FormModule) {
+		return nil
+	}
+
+	// forms are child objects which elements hold module
+	for _, cl := range tree.classLists() {
+		for _, id := range cl.IDs {
+			text, ok, err := cd.formModuleText(id)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			formTree, err := cd.tree(id)
+			if err != nil {
+				return err
+			}
+			formName, _, ok := formTree.objectHeader(id)
+			if !ok {
+				return fmt.Errorf("name of form %s is not found in container", id)
+			}
+			cd.addFile(path.Join(objectDir, "Forms", formName, "Ext", "Form", "Module.bsl"), text)
+		}
+	}
+
+	return nil
+}
+
+// dumpSubsystem adds xml file of subsystem and of all its descendants to folder of file system
+func (cd *containerDump) dumpSubsystem(dir string, co *containerObject) error {
+
+	tree, err := cd.tree(co.ID)
+	if err != nil {
+		return err
+	}
+
+	ds := &dumpSubsystem{Name: co.Name}
+	for _, synonym := range co.Synonyms {
+		ds.Synonyms = append(ds.Synonyms, dumpSynonym{Content: synonym})
+	}
+
+	var children []*containerObject
+	childIDs := make(map[string]bool)
+
+	for _, cl := range tree.classLists() {
+		if metadataClassIDs[cl.ClassID] != "Subsystem" {
+			continue
+		}
+		for _, id := range cl.IDs {
+			child, err := cd.readObject(id, co.Type)
+			if err != nil {
+				return err
+			}
+			children = append(children, child)
+			childIDs[id] = true
+			ds.Children = append(ds.Children, child.Name)
+		}
+	}
+
+	// content of subsystem is a list of references to top level objects
+	added := make(map[string]bool)
+	for _, value := range tree.values() {
+		object, ok := cd.objects[value]
+		if !ok || childIDs[value] || added[value] {
+			continue
+		}
+		added[value] = true
+		ds.Content = append(ds.Content, object.Type.Name+"."+object.Name)
+	}
+
+	if err = cd.addXMLFile(path.Join(dir, co.Name+".xml"), ds); err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err = cd.dumpSubsystem(path.Join(dir, co.Name, "Subsystems"), child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package finder
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ids of metadata objects of test configuration in container
+const (
+	testConfigurationID = "11111111-0000-0000-0000-000000000001"
+	testCatalogID       = "11111111-0000-0000-0000-000000000002"
+	testFormID          = "11111111-0000-0000-0000-000000000003"
+	testCommonModuleID  = "11111111-0000-0000-0000-000000000004"
+	testDocumentID      = "11111111-0000-0000-0000-000000000005"
+	testSubsystemID     = "11111111-0000-0000-0000-000000000006"
+	testChildID         = "11111111-0000-0000-0000-000000000007"
+	testOtherID         = "11111111-0000-0000-0000-000000000008"
+	testCommandID       = "11111111-0000-0000-0000-000000000009"
+	testCommonCommandID = "11111111-0000-0000-0000-000000000010"
+	testManagedFormID   = "11111111-0000-0000-0000-000000000011"
+	testTemplateID      = "11111111-0000-0000-0000-000000000012"
+	testFormClassID     = "fdf816d2-1ead-11d5-b975-0050bae0a95d"
+	testCommandClassID  = "4fe87c89-9ad4-43f6-9fdb-9dc83b3879c6"
+	testTemplateClassID = "3daea016-69b7-4ed4-9453-127911372fe6"
+	testRefClassID      = "157fa490-4ce9-11d4-9415-008048da11f9"
+)
+
+// testObjectHeader returns properties of metadata object in 1C internal format
+func testObjectHeader(id string, name string, synonym string) string {
+	return fmt.Sprintf("{0,{0,0,%s},\"%s\",{1,\"ru\",\"%s\"},\"\"}", id, name, synonym)
+}
+
+// testModule returns element of container with module text in nested container
+func testModule(part string, text string) []byte {
+	return buildTestContainer([]containerElement{
+		{Name: "info", Data: []byte("{3,1,{0},\"\",0}")},
+		{Name: part, Data: []byte("\uFEFF" + text)},
+	}, false, 512)
+}
+
+// writeTestConfiguration writes .cf file with configuration modules, catalog with command, document with managed form,
+// common module, common command and subsystems
+func writeTestConfiguration(t *testing.T, filename string) {
+
+	elements := []containerElement{
+		{Name: "root", Data: []byte("\uFEFF{2," + testConfigurationID + ",}")},
+		{Name: testConfigurationID, Data: []byte(fmt.Sprintf("\uFEFF{2,{1,%s},{%s,1,%s},{%s,1,%s},{%s,1,%s},{%s,1,%s},{%s,2,%s,%s}}",
+			testObjectHeader(testConfigurationID, "Конфигурация", "Конфигурация"),
+			"cf4abea6-37b2-11d4-940f-008048da11f9", testCatalogID,
+			"061d872a-5787-460e-95ac-ed74ea3a3e84", testDocumentID,
+			"0fe48980-252d-11d6-a3c7-0050bae0a776", testCommonModuleID,
+			"2f1a5187-fb0e-4b05-9489-dc5dd6412348", testCommonCommandID,
+			"37f2fa9a-b276-11d4-9435-004095e12fc7", testSubsystemID, testOtherID))},
+		{Name: testConfigurationID + ".6", Data: testModule("text", "Процедура ПриНачалеРаботыСистемы()\r\nКонецПроцедуры\r\n")},
+		{Name: testConfigurationID + ".7", Data: testModule("text", "Процедура УстановкаПараметровСеанса(Параметры)\r\nКонецПроцедуры\r\n")},
+		{Name: testCatalogID, Data: []byte(fmt.Sprintf("{1,{3,%s},{%s,1,%s},{%s,1,{1,%s}}}",
+			testObjectHeader(testCatalogID, "Справочник1", "Справочник 1"), testFormClassID, testFormID,
+			testCommandClassID, testObjectHeader(testCommandID, "Команда1", "Команда 1")))},
+		{Name: testCommandID + ".2", Data: testModule("text", "&НаКлиенте\r\nПроцедура ОбработкаКоманды(Параметр)\r\nКонецПроцедуры\r\n")},
+		{Name: testCommonCommandID, Data: []byte(fmt.Sprintf("{1,{1,%s}}", testObjectHeader(testCommonCommandID, "ОбщаяКоманда1", "Общая команда")))},
+		{Name: testCommonCommandID + ".2", Data: testModule("text", "&НаКлиенте\r\nПроцедура ОбработкаКоманды(Параметр)\r\nКонецПроцедуры\r\n")},
+		{Name: testCatalogID + ".0", Data: testModule("text", "Процедура ПередЗаписью(Отказ)\r\nКонецПроцедуры\r\n")},
+		{Name: testCatalogID + ".2", Data: []byte("\uFEFFФункция Представление() Экспорт\r\nКонецФункции\r\n")},
+		{Name: testFormID, Data: []byte(fmt.Sprintf("{1,{1,%s}}", testObjectHeader(testFormID, "ФормаЭлемента", "Форма элемента")))},
+		{Name: testFormID + ".0", Data: testModule("module", "&НаКлиенте\r\nПроцедура Команда1(Команда)\r\nКонецПроцедуры\r\n")},
+		{Name: testDocumentID, Data: []byte(fmt.Sprintf("{1,{2,%s},{%s,1,%s},{%s,1,%s}}",
+			testObjectHeader(testDocumentID, "Документ1", "Документ 1"), testFormClassID, testManagedFormID, testTemplateClassID, testTemplateID))},
+		{Name: testManagedFormID, Data: []byte(fmt.Sprintf("{1,{1,%s}}", testObjectHeader(testManagedFormID, "ФормаДокумента", "Форма документа")))},
+		{Name: testManagedFormID + ".0", Data: []byte("\uFEFF{4,{1,0,{\"Форма\"}},\"&НаКлиенте\r\nПроцедура ПриОткрытии(Отказ)\r\n\tСообщить(\"\"Открыта\"\");\r\nКонецПроцедуры\r\n\",0}")},
+		{Name: testTemplateID, Data: []byte(fmt.Sprintf("{1,{1,%s}}", testObjectHeader(testTemplateID, "Макет", "Макет")))},
+		{Name: testTemplateID + ".0", Data: []byte("\uFEFF{\"#\",{1,\"Текст макета\"}}")},
+		{Name: testDocumentID + ".0", Data: testModule("text", "Процедура ОбработкаПроведения(Отказ)\r\nКонецПроцедуры\r\n")},
+		{Name: testCommonModuleID, Data: []byte(fmt.Sprintf("{1,{2,%s,0,1}}", testObjectHeader(testCommonModuleID, "рн_ОбщийМодуль", "Общий модуль")))},
+		{Name: testCommonModuleID + ".0", Data: testModule("text", "Функция Версия() Экспорт\r\nКонецФункции\r\n")},
+		{Name: testSubsystemID, Data: []byte(fmt.Sprintf("{1,{1,%s,1,{1,{\"#\",%s,{1,%s}}}},{%s,1,%s}}",
+			testObjectHeader(testSubsystemID, "рн_Супер", "Супер подсистема"), testRefClassID, testCatalogID,
+			"37f2fa9a-b276-11d4-9435-004095e12fc7", testChildID))},
+		{Name: testChildID, Data: []byte(fmt.Sprintf("{1,{1,%s,1,{1,{\"#\",%s,{1,%s}}}},{%s,0}}",
+			testObjectHeader(testChildID, "Дочерняя", "Дочерняя подсистема"), testRefClassID, testCommonModuleID,
+			"37f2fa9a-b276-11d4-9435-004095e12fc7"))},
+		{Name: testOtherID, Data: []byte(fmt.Sprintf("{1,{1,%s,1,{2,{\"#\",%s,{1,%s}},{\"#\",%s,{1,%s}}}},{%s,0}}",
+			testObjectHeader(testOtherID, "Прочее", "Прочее"), testRefClassID, testDocumentID, testRefClassID, testCommonCommandID,
+			"37f2fa9a-b276-11d4-9435-004095e12fc7"))},
+	}
+
+	assert.NoError(t, ioutil.WriteFile(filename, buildTestContainer(elements, true, 512), 0644))
+}
+
+func TestContainerDump(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	cf := path.Join(tempDir, "1Cv8.cf")
+	writeTestConfiguration(t, cf)
+
+	fndr := NewFinder(cf, "рн_")
+	assert.NoError(t, fndr.CheckSources())
+	assert.Equal(t, LayoutDesigner, fndr.Layout)
+
+	c, err := readConfiguration(fndr.files, fndr.configurationFilePath())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Конфигурация", c.Name)
+		assert.Equal(t, 6, len(c.ChildObjects.Items))
+	}
+
+	s, err := readSubsystem(fndr.files, path.Join(cf, "Subsystems/рн_Супер.xml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"Супер подсистема"}, s.Synonyms)
+		assert.Equal(t, []string{"Catalog.Справочник1"}, s.Content)
+		assert.Equal(t, []string{"Дочерняя"}, s.Children)
+	}
+
+	assert.Equal(t, []string{
+		"Catalogs/Справочник1/Commands/Команда1/Ext/CommandModule.bsl",
+		"Catalogs/Справочник1/Ext/ManagerModule.bsl",
+		"Catalogs/Справочник1/Ext/ObjectModule.bsl",
+		"Catalogs/Справочник1/Forms/ФормаЭлемента/Ext/Form/Module.bsl",
+	}, fndr.getBslFilesPaths())
+
+	fndr = NewFinder(cf, "рн_")
+	fndr.Recursive = true
+	assert.Contains(t, fndr.getBslFilesPaths(), "CommonModules/рн_ОбщийМодуль/Ext/Module.bsl")
+
+	fndr = NewFinder(cf, "Супер*")
+	fndr.Match = MatchGlob
+	assert.Equal(t, 4, len(fndr.getBslFilesPaths()))
+
+	// managed forms hold module in description, templates are skipped, common commands hold module
+	fndr = NewFinder(cf, "Прочее")
+	assert.Equal(t, []string{
+		"CommonCommands/ОбщаяКоманда1/Ext/CommandModule.bsl",
+		"Documents/Документ1/Ext/ObjectModule.bsl",
+		"Documents/Документ1/Forms/ФормаДокумента/Ext/Form/Module.bsl",
+	}, fndr.getBslFilesPaths())
+	data, err := fndr.files.ReadFile(path.Join(cf, "Documents/Документ1/Forms/ФормаДокумента/Ext/Form/Module.bsl"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Сообщить(\"Открыта\");")
+
+	// modules of configuration
+	fndr = NewFinder(cf, "рн_")
+	fndr.ConfigModules = []string{ConfigModulesAll}
+	assert.Equal(t, []string{"Ext/ManagedApplicationModule.bsl", "Ext/SessionModule.bsl"}, fndr.getConfigurationModulesFilesPaths())
+
+	fndr = NewFinder(cf, "рн_")
+	fndr.Scope = ScopeNames
+	assert.Equal(t, []string{"CommonModules/рн_ОбщийМодуль/Ext/Module.bsl"}, fndr.getBslFilesPaths())
+
+	assert.Empty(t, NewFinder(cf, "").getOrphanObjectsNames())
+
+	// modules are extracted to folder for sonar-scanner
+	extractDir := path.Join(tempDir, "src")
+	fndr = NewFinder(cf, "рн_")
+	fndr.Abspath = true
+	fndr.Base = extractDir
+	assert.NoError(t, fndr.ExtractSources(extractDir))
+	for _, bslFilePath := range fndr.getBslFilesPaths() {
+		data, err := ioutil.ReadFile(bslFilePath)
+		assert.NoError(t, err)
+		assert.True(t, hasCode(osFiles, bslFilePath), bslFilePath)
+		assert.NotEmpty(t, data)
+	}
+	assert.True(t, exists(osFiles, path.Join(extractDir, "Configuration.xml")))
+
+	assert.Error(t, NewFinder(AbsPathTestSrcFolder, "рн_").ExtractSources(extractDir))
+
+	assert.True(t, HasObjectBelonging(cf))
+	assert.False(t, NewFinder(cf, "").IsExtension())
+
+	// file of extension is read as extension, belonging of its objects is not read
+	cfe := path.Join(tempDir, "Расш1.cfe")
+	writeTestConfiguration(t, cfe)
+	assert.False(t, HasObjectBelonging(cfe))
+	fndr = NewFinder(cfe, "рн_")
+	fndr.Extension = true
+	assert.True(t, fndr.IsExtension())
+	assert.Equal(t, 4, len(fndr.getBslFilesPaths()))
+
+	// broken container
+	brokenCf := path.Join(tempDir, "broken.cf")
+	assert.NoError(t, ioutil.WriteFile(brokenCf, []byte("broken"), 0644))
+	err = NewFinder(brokenCf, "рн_").CheckSources()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Can't read archive")
+	}
+}
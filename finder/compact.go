@@ -0,0 +1,162 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// compactGroup is a folder of metadata type or object with all its module files of one extension
+type compactGroup struct {
+	glob  string   // pattern like "Catalogs/Справочник1/**/*.bsl"
+	files []string // output paths to all module files matched by pattern
+}
+
+// compactGlob returns pattern for all files with extension in folder and its subfolders
+func (f *Finder) compactGlob(dir string, ext string) string {
+	return filepath.ToSlash(f.outputPath(dir)) + "/**/*" + ext
+}
+
+// getCompactGroups returns groups of module files of metadata types and objects by extensions,
+// group of type goes before groups of its objects
+func (f *Finder) getCompactGroups() []compactGroup {
+
+	var groups []compactGroup
+
+	for _, mt := range MetadataTypes {
+
+		if len(mt.ModuleKinds) == 0 || len(mt.Dir) == 0 {
+			continue
+		}
+		typeDir := path.Join(f.srcdir, mt.Dir)
+		if !exists(f.files, typeDir) {
+			continue
+		}
+
+		for _, pattern := range f.modulePatterns() {
+
+			ext := strings.TrimPrefix(pattern, "*")
+			typeGroup := compactGroup{glob: f.compactGlob(typeDir, ext)}
+			objectGroups := make(map[string]*compactGroup)
+			var objectsNames []string
+
+			for _, file := range f.getSliceFiles(typeDir, pattern) {
+
+				relPath, err := filepath.Rel(typeDir, file)
+				if err != nil {
+					continue
+				}
+				outputFile := f.outputPath(file)
+				typeGroup.files = append(typeGroup.files, outputFile)
+
+				// file outside of object folders is matched only by glob of metadata type
+				parts := strings.Split(filepath.ToSlash(relPath), "/")
+				if len(parts) < 2 {
+					continue
+				}
+
+				og, ok := objectGroups[parts[0]]
+				if !ok {
+					og = &compactGroup{glob: f.compactGlob(path.Join(typeDir, parts[0]), ext)}
+					objectGroups[parts[0]] = og
+					objectsNames = append(objectsNames, parts[0])
+				}
+				og.files = append(og.files, outputFile)
+			}
+
+			groups = append(groups, typeGroup)
+			for _, name := range objectsNames {
+				groups = append(groups, *objectGroups[name])
+			}
+		}
+	}
+
+	return groups
+}
+
+// compactPaths replaces output paths to module files by glob of metadata type or object
+// when all module files of the type or object are selected, so patterns match exactly the same files
+func (f *Finder) compactPaths(filesPaths []string) []string {
+
+	selected := make(map[string]bool)
+	for _, filePath := range filesPaths {
+		selected[filePath] = true
+	}
+
+	// glob replaces the first of its files, other files are removed
+	replacements := make(map[string]string)
+	for _, group := range f.getCompactGroups() {
+
+		// pattern is not shorter than one path
+		if len(group.files) < 2 {
+			continue
+		}
+
+		allSelected := true
+		for _, file := range group.files {
+			if !selected[file] {
+				allSelected = false
+				break
+			}
+			if _, ok := replacements[file]; ok {
+				// files are already replaced by glob of metadata type
+				allSelected = false
+				break
+			}
+		}
+		if !allSelected {
+			continue
+		}
+
+		for _, file := range group.files {
+			replacements[file] = ""
+		}
+		replacements[group.files[0]] = group.glob
+	}
+
+	var compactedPaths []string
+	for _, filePath := range filesPaths {
+		replacement, ok := replacements[filePath]
+		if !ok {
+			compactedPaths = append(compactedPaths, filePath)
+			continue
+		}
+		if len(replacement) != 0 {
+			compactedPaths = append(compactedPaths, replacement)
+		}
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Количество путей после сжатия: %d", len(compactedPaths))
+	}
+
+	return compactedPaths
+}
+
+// getCompactedBslFilesPaths returns output paths to bsl files of source root, compacted to globs if it is set
+func (f *Finder) getCompactedBslFilesPaths() []string {
+
+	filesPaths := f.getBslFilesPaths()
+	if !f.Compact {
+		return filesPaths
+	}
+
+	return f.compactPaths(filesPaths)
+}
@@ -0,0 +1,103 @@
+package finder
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBslFiles returns sorted relative paths to all bsl files of folder
+func testBslFiles(t *testing.T, srcdir string) []string {
+
+	var allFiles []string
+	err := filepath.Walk(srcdir, func(wpath string, info fs.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(wpath, ".bsl") {
+			relPath, _ := filepath.Rel(srcdir, wpath)
+			allFiles = append(allFiles, filepath.ToSlash(relPath))
+		}
+		return err
+	})
+	assert.NoError(t, err)
+
+	sort.Strings(allFiles)
+	return allFiles
+}
+
+// expandCompactedPaths returns relative paths to all bsl files of folder matched by paths and globs
+func expandCompactedPaths(t *testing.T, srcdir string, compactedPaths []string) []string {
+
+	var filesPaths []string
+	for _, file := range testBslFiles(t, srcdir) {
+		for _, pattern := range compactedPaths {
+			pattern = filepath.ToSlash(pattern)
+			if idx := strings.Index(pattern, "/**/*"); idx >= 0 {
+				if strings.HasPrefix(file, pattern[:idx+1]) && strings.HasSuffix(file, pattern[idx+len("/**/*"):]) {
+					filesPaths = append(filesPaths, file)
+					break
+				}
+				continue
+			}
+			if file == pattern {
+				filesPaths = append(filesPaths, file)
+				break
+			}
+		}
+	}
+
+	return filesPaths
+}
+
+func TestCompactPaths(t *testing.T) {
+	testTable := []struct {
+		srcdir        string
+		phrases       string
+		scope         string
+		moduleKinds   []ModuleKind
+		expectedGlobs []string
+	}{
+		{AbsPathTestSrcFolder, "рн_", ScopeSubsystems, nil, []string{"Catalogs/Справочник3/**/*.bsl", "Reports/Отчет6/**/*.bsl"}},
+		{AbsPathTestSrcFolder, "Справочник Обработка", ScopeNames, nil, []string{"Catalogs/**/*.bsl", "DataProcessors/**/*.bsl"}},
+		{AbsPathTestSrcFolder, "Справочник", ScopeNames, []ModuleKind{ObjectModule}, nil},
+		{AbsPathTestEDTFolder, "рн_", ScopeSubsystems, nil, []string{"Catalogs/Справочник1/**/*.bsl", "Documents/**/*.bsl"}},
+	}
+
+	for _, testCase := range testTable {
+
+		fndr := NewFinder(testCase.srcdir, testCase.phrases)
+		fndr.Scope = testCase.scope
+		fndr.ModuleKinds = testCase.moduleKinds
+		filesPaths := fndr.getBslFilesPaths()
+
+		compactedPaths := fndr.compactPaths(append([]string{}, filesPaths...))
+
+		for _, glob := range testCase.expectedGlobs {
+			assert.Contains(t, compactedPaths, glob, testCase.phrases)
+		}
+		if testCase.expectedGlobs == nil {
+			assert.Equal(t, filesPaths, compactedPaths, testCase.phrases)
+		} else {
+			assert.Less(t, len(compactedPaths), len(filesPaths), testCase.phrases)
+		}
+
+		// globs match exactly the same files
+		sort.Strings(filesPaths)
+		assert.Equal(t, filesPaths, expandCompactedPaths(t, testCase.srcdir, compactedPaths), testCase.phrases)
+	}
+}
+
+func TestCompactPathsWithPrefix(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "Справочник")
+	fndr.Scope = ScopeNames
+	fndr.Compact = true
+	fndr.Prefix = "src/cf"
+	assert.Equal(t, []string{"src/cf/Catalogs/**/*.bsl"}, fndr.getAllBslFilesPaths())
+
+	fndr.Prefix = ""
+	fndr.Abspath = true
+	assert.Equal(t, []string{filepath.ToSlash(AbsPathTestSrcFolder) + "/Catalogs/**/*.bsl"}, fndr.getAllBslFilesPaths())
+}
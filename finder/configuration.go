@@ -0,0 +1,197 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"path"
+	"strings"
+)
+
+// Scopes of metadata objects selection
+const (
+	ScopeSubsystems = "subsystems" // objects from content of matched subsystems
+	ScopeNames      = "names"      // objects of configuration which names match phrases
+	ScopeBoth       = "both"       // union of subsystems and names scopes
+)
+
+// ConfigModulesAll is a name for selection of all configuration modules
+const ConfigModulesAll = "all"
+
+// configurationItem is a child object of configuration like <Catalog>Справочник1</Catalog>
+type configurationItem struct {
+	XMLName xml.Name
+	Name    string `xml:",chardata"`
+}
+
+// configuration is a structure for unmarshal Configuration.xml file
+type configuration struct {
+	FormatVersion              string `xml:"version,attr"`
+	Name                       string `xml:"Configuration>Properties>Name"`
+	ObjectBelonging            string `xml:"Configuration>Properties>ObjectBelonging"`
+	NamePrefix                 string `xml:"Configuration>Properties>NamePrefix"`
+	CompatibilityMode          string `xml:"Configuration>Properties>CompatibilityMode"`
+	ExtensionCompatibilityMode string `xml:"Configuration>Properties>ConfigurationExtensionCompatibilityMode"`
+	ChildObjects               struct {
+		Items []configurationItem `xml:",any"`
+	} `xml:"Configuration>ChildObjects"`
+}
+
+// edtConfiguration is a structure for unmarshal Configuration.mdo file of 1C:EDT project
+type edtConfiguration struct {
+	Name                       string              `xml:"name"`
+	ObjectBelonging            string              `xml:"objectBelonging"`
+	NamePrefix                 string              `xml:"namePrefix"`
+	CompatibilityMode          string              `xml:"compatibilityMode"`
+	ExtensionCompatibilityMode string              `xml:"configurationExtensionCompatibilityMode"`
+	Items                      []configurationItem `xml:",any"`
+}
+
+// readConfiguration reads and unmarshal Configuration.xml or Configuration.mdo file
+func readConfiguration(files fileSystem, filename string) (*configuration, error) {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if path.Ext(filename) == ".mdo" {
+		ec := &edtConfiguration{}
+		err = xml.Unmarshal(byteValue, ec)
+		if err != nil {
+			return nil, err
+		}
+		return ec.toConfiguration(), nil
+	}
+
+	c := &configuration{}
+	err = xml.Unmarshal(byteValue, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// toConfiguration converts child objects like <catalogs>Catalog.Справочник1</catalogs> to Designer form
+func (ec *edtConfiguration) toConfiguration() *configuration {
+
+	c := &configuration{
+		Name:                       ec.Name,
+		ObjectBelonging:            ec.ObjectBelonging,
+		NamePrefix:                 ec.NamePrefix,
+		CompatibilityMode:          ec.CompatibilityMode,
+		ExtensionCompatibilityMode: ec.ExtensionCompatibilityMode,
+	}
+
+	for _, item := range ec.Items {
+
+		typeName, objectName, ok := splitMetadataName(strings.TrimSpace(item.Name))
+		if !ok {
+			continue
+		}
+		mt, ok := LookupMetadataType(typeName)
+		if !ok {
+			continue
+		}
+
+		// list of child objects is named like folder of metadata class: catalogs, chartsOfAccounts
+		if !strings.EqualFold(item.XMLName.Local, mt.Dir) {
+			continue
+		}
+
+		c.ChildObjects.Items = append(c.ChildObjects.Items, configurationItem{
+			XMLName: xml.Name{Local: mt.Name},
+			Name:    objectName,
+		})
+	}
+
+	return c
+}
+
+// getObjectsNamesFromConfiguration returns full names of configuration objects which names match phrases
+func (f *Finder) getObjectsNamesFromConfiguration(phrases []string, exclude []string) []string {
+
+	var objectsNames []string
+
+	if len(phrases) == 0 {
+		return []string{}
+	}
+
+	c, err := readConfiguration(f.files, f.configurationFilePath())
+	if err != nil {
+		println(err.Error())
+		return []string{}
+	}
+
+	for _, item := range c.ChildObjects.Items {
+
+		// only objects that can hold bsl modules
+		mt, ok := LookupMetadataType(item.XMLName.Local)
+		if !ok || len(mt.ModuleKinds) == 0 {
+			continue
+		}
+
+		if !matchAnyPhrase(f.Match, phrases, item.Name) || matchAnyPhrase(f.Match, exclude, item.Name) {
+			continue
+		}
+
+		objectsNames = append(objectsNames, mt.Name+"."+item.Name)
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Найдено объектов конфигурации по именам: %d", len(objectsNames))
+	}
+
+	return objectsNames
+}
+
+// getConfigurationModulesFilesPaths returns paths to selected modules of configuration (application, session and etc.)
+func (f *Finder) getConfigurationModulesFilesPaths() []string {
+
+	var modulesFilesPaths []string
+
+	for _, kind := range ConfigurationModuleKinds {
+
+		selected := false
+		for _, name := range f.ConfigModules {
+			if name == ConfigModulesAll || name == string(kind) {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			continue
+		}
+
+		moduleFilePath := path.Join(f.configurationModulesDir(), string(kind)+".bsl")
+
+		// check file exist
+		if !exists(f.files, moduleFilePath) {
+			continue
+		}
+
+		modulesFilesPaths = append(modulesFilesPaths, f.outputPath(moduleFilePath))
+	}
+
+	if f.Logging && len(f.ConfigModules) != 0 {
+		f.Logger.Printf(">>> Количество модулей конфигурации для проверки: %d", len(modulesFilesPaths))
+	}
+
+	return modulesFilesPaths
+}
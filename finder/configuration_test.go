@@ -0,0 +1,78 @@
+package finder
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfiguration(t *testing.T) {
+	c, err := readConfiguration(osFiles, path.Join(AbsPathTestSrcFolder, "Configuration.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Конфигурация", c.Name)
+	assert.Equal(t, 41, len(c.ChildObjects.Items))
+	assert.Equal(t, "Language", c.ChildObjects.Items[0].XMLName.Local)
+	assert.Equal(t, "Русский", c.ChildObjects.Items[0].Name)
+
+	_, err = readConfiguration(osFiles, path.Join(AbsPathTestSrcFolder, "NotExist.xml"))
+	assert.Error(t, err)
+}
+
+func TestGetObjectsNamesFromConfiguration(t *testing.T) {
+	testTable := []struct {
+		match         string
+		phrases       []string
+		exclude       []string
+		expectedCount int
+	}{
+		{MatchPrefix, []string{"Справочник1"}, nil, 2},
+		{MatchPrefix, []string{"Справочник", "Отчет"}, []string{"Справочник1"}, 15},
+		{MatchPrefix, []string{"Русский", "рн_"}, nil, 0},
+		{MatchGlob, []string{"Документ?"}, nil, 9},
+		{MatchRegexp, []string{"^Обработка(1|2)$"}, nil, 2},
+		{MatchPrefix, nil, nil, 0},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, "")
+		fndr.Match = testCase.match
+		objectsNames := fndr.getObjectsNamesFromConfiguration(testCase.phrases, testCase.exclude)
+		assert.Equal(t, testCase.expectedCount, len(objectsNames), testCase.phrases)
+	}
+}
+
+func TestGetSliceMetadataNameScope(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "Справочник1 !Справочник10")
+	fndr.Scope = ScopeNames
+	assert.Equal(t, []string{"Catalog.Справочник1"}, fndr.getSliceMetadataName())
+
+	fndr = NewFinder(AbsPathTestSrcFolder, phrases+" Отчет")
+	fndr.Scope = ScopeBoth
+	assert.Equal(t, CountGetListMetadataName+3, len(fndr.getSliceMetadataName()))
+}
+
+func TestGetConfigurationModulesFilesPaths(t *testing.T) {
+	testTable := []struct {
+		configModules []string
+		expectedPaths []string
+	}{
+		{nil, nil},
+		{[]string{ConfigModulesAll}, []string{"Ext/ManagedApplicationModule.bsl", "Ext/SessionModule.bsl"}},
+		{[]string{"SessionModule"}, []string{"Ext/SessionModule.bsl"}},
+		{[]string{"ExternalConnectionModule"}, nil},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, "")
+		fndr.ConfigModules = testCase.configModules
+		assert.Equal(t, testCase.expectedPaths, fndr.getConfigurationModulesFilesPaths(), testCase.configModules)
+	}
+
+	fndr := NewFinder(AbsPathTestSrcFolder, phrases)
+	fndr.ConfigModules = []string{ConfigModulesAll}
+	fndr.Abspath = true
+	bslFilesPaths := fndr.getBslFilesPaths()
+	assert.Equal(t, CountGetBslFilesPaths+2, len(bslFilesPaths))
+	assert.Equal(t, path.Join(AbsPathTestSrcFolder, "Ext/ManagedApplicationModule.bsl"), bslFilesPaths[0])
+}
@@ -0,0 +1,332 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Structure of 1C container (.cf, .cfe, .epf files):
+// container header (16 bytes), then documents stored in chains of blocks,
+// the first document is a table of contents with addresses of headers and data of elements
+const (
+	containerHeaderSize = 16
+	blockHeaderSize     = 31
+	containerEnd        = 0x7fffffff
+	tocItemSize         = 12
+	elemHeaderSize      = 20
+)
+
+// containerElement is a named file of 1C container
+type containerElement struct {
+	Name string
+	Data []byte
+}
+
+// container is an access to elements of 1C container by its table of contents,
+// documents of elements are read and inflated on demand
+type container struct {
+	reader     io.ReaderAt
+	size       int
+	compressed bool
+	names      []string       // names of elements in order of table of contents
+	addrs      map[string]int // addresses of data of elements by names
+}
+
+// isContainer checks that data starts with header of 1C container
+func isContainer(data []byte) bool {
+	if len(data) < containerHeaderSize+blockHeaderSize {
+		return false
+	}
+	return binary.LittleEndian.Uint32(data) == containerEnd && data[containerHeaderSize] == '\r' && data[containerHeaderSize+1] == '\n'
+}
+
+// readAt reads bytes of container at address
+func (c *container) readAt(addr int, size int) ([]byte, error) {
+
+	if addr < 0 || size < 0 || addr+size > c.size {
+		return nil, fmt.Errorf("block address %d is out of container", addr)
+	}
+
+	data := make([]byte, size)
+	if _, err := c.reader.ReadAt(data, int64(addr)); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readBlockHeader reads header of block like "\r\n<doc size> <block size> <next block> \r\n" in hex
+func (c *container) readBlockHeader(addr int) (docSize int, blockSize int, next int, err error) {
+
+	header, err := c.readAt(addr, blockHeaderSize)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if header[0] != '\r' || header[1] != '\n' || header[29] != '\r' || header[30] != '\n' {
+		return 0, 0, 0, fmt.Errorf("invalid header of block at address %d", addr)
+	}
+
+	var values [3]int
+	for idx := range values {
+		value, err := strconv.ParseUint(string(header[2+idx*9:10+idx*9]), 16, 32)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid header of block at address %d: %s", addr, err)
+		}
+		values[idx] = int(value)
+	}
+
+	return values[0], values[1], values[2], nil
+}
+
+// readDocument reads document of container stored in chain of blocks started at address,
+// chains with empty or repeated blocks are rejected
+func (c *container) readDocument(addr int) ([]byte, error) {
+
+	docSize, blockSize, next, err := c.readBlockHeader(addr)
+	if err != nil {
+		return nil, err
+	}
+	if docSize > c.size {
+		return nil, fmt.Errorf("size of document %d at address %d is greater than container", docSize, addr)
+	}
+
+	visited := map[int]bool{}
+	doc := make([]byte, 0, docSize)
+	for {
+		if blockSize == 0 {
+			return nil, fmt.Errorf("empty block at address %d", addr)
+		}
+		visited[addr] = true
+
+		size := blockSize
+		if rest := docSize - len(doc); size > rest {
+			size = rest
+		}
+		block, err := c.readAt(addr+blockHeaderSize, size)
+		if err != nil {
+			return nil, fmt.Errorf("block at address %d is out of container", addr)
+		}
+		doc = append(doc, block...)
+
+		if next == containerEnd || len(doc) >= docSize {
+			return doc, nil
+		}
+
+		if visited[next] {
+			return nil, fmt.Errorf("chain of blocks loops at address %d", next)
+		}
+		addr = next
+		if _, blockSize, next, err = c.readBlockHeader(addr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// elementName reads name of element from its header: dates of creation and modification, reserved field
+// and name in UTF-16LE terminated by zeros
+func elementName(header []byte) string {
+
+	if len(header) <= elemHeaderSize {
+		return ""
+	}
+
+	nameBytes := header[elemHeaderSize:]
+	chars := make([]uint16, 0, len(nameBytes)/2)
+	for idx := 0; idx+1 < len(nameBytes); idx += 2 {
+		char := binary.LittleEndian.Uint16(nameBytes[idx:])
+		if char == 0 {
+			break
+		}
+		chars = append(chars, char)
+	}
+
+	return string(utf16.Decode(chars))
+}
+
+// openContainer reads table of contents of 1C container, data of elements is read by element
+func openContainer(reader io.ReaderAt, size int, compressed bool) (*container, error) {
+
+	c := &container{reader: reader, size: size, compressed: compressed, addrs: make(map[string]int)}
+
+	header, err := c.readAt(0, containerHeaderSize+blockHeaderSize)
+	if err != nil || !isContainer(header) {
+		return nil, errors.New("data is not a 1C container")
+	}
+
+	toc, err := c.readDocument(containerHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := 0; idx+tocItemSize <= len(toc); idx += tocItemSize {
+
+		headerAddr := int(binary.LittleEndian.Uint32(toc[idx:]))
+		dataAddr := int(binary.LittleEndian.Uint32(toc[idx+4:]))
+
+		elemHeader, err := c.readDocument(headerAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		name := elementName(elemHeader)
+		c.names = append(c.names, name)
+		c.addrs[name] = dataAddr
+	}
+
+	return c, nil
+}
+
+// element reads data of element by name, data is inflated if container is compressed
+func (c *container) element(name string) ([]byte, bool, error) {
+
+	addr, ok := c.addrs[name]
+	if !ok {
+		return nil, false, nil
+	}
+	if addr == containerEnd {
+		return []byte{}, true, nil
+	}
+
+	data, err := c.readDocument(addr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if c.compressed && len(data) != 0 {
+		if data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(data))); err != nil {
+			return nil, false, fmt.Errorf("can't inflate element %s: %s", name, err)
+		}
+	}
+
+	return data, true, nil
+}
+
+// readContainer reads all elements of 1C container in memory like nested container of module
+func readContainer(data []byte, compressed bool) ([]containerElement, error) {
+
+	c, err := openContainer(bytes.NewReader(data), len(data), compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []containerElement
+	for _, name := range c.names {
+		element := containerElement{Name: name}
+		if element.Data, _, err = c.element(name); err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// braceNode is a value of 1C internal format like {1,"text",{2,uuid}}
+type braceNode struct {
+	Value    string       // string or bare value like number or uuid
+	IsString bool         // value is a quoted string
+	Items    []*braceNode // items of list
+	IsList   bool         // node is a list in braces
+}
+
+// parseBraces parses text in 1C internal format
+func parseBraces(text string) (*braceNode, error) {
+
+	text = strings.TrimPrefix(text, "\uFEFF")
+	pos := 0
+
+	var parseValue func() (*braceNode, error)
+	skipSpaces := func() {
+		for pos < len(text) && strings.ContainsRune(" \t\r\n", rune(text[pos])) {
+			pos++
+		}
+	}
+
+	parseValue = func() (*braceNode, error) {
+
+		skipSpaces()
+		if pos >= len(text) {
+			return nil, errors.New("unexpected end of text")
+		}
+
+		switch text[pos] {
+		case '{':
+			node := &braceNode{IsList: true}
+			pos++
+			for {
+				skipSpaces()
+				if pos < len(text) && text[pos] == '}' {
+					pos++
+					return node, nil
+				}
+				item, err := parseValue()
+				if err != nil {
+					return nil, err
+				}
+				node.Items = append(node.Items, item)
+				skipSpaces()
+				if pos >= len(text) {
+					return nil, errors.New("unexpected end of text")
+				}
+				switch text[pos] {
+				case ',':
+					pos++
+				case '}':
+				default:
+					return nil, fmt.Errorf("unexpected symbol %q at position %d", text[pos], pos)
+				}
+			}
+		case '"':
+			var value strings.Builder
+			pos++
+			for {
+				end := strings.IndexByte(text[pos:], '"')
+				if end < 0 {
+					return nil, errors.New("unterminated string")
+				}
+				value.WriteString(text[pos : pos+end])
+				pos += end + 1
+				// quote inside of string is doubled
+				if pos < len(text) && text[pos] == '"' {
+					value.WriteByte('"')
+					pos++
+					continue
+				}
+				return &braceNode{Value: value.String(), IsString: true}, nil
+			}
+		default:
+			start := pos
+			for pos < len(text) && text[pos] != ',' && text[pos] != '}' {
+				pos++
+			}
+			return &braceNode{Value: strings.TrimSpace(text[start:pos])}, nil
+		}
+	}
+
+	return parseValue()
+}
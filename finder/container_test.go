@@ -0,0 +1,222 @@
+package finder
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestDocument writes document to chain of blocks of fixed size and returns address of the first block
+func writeTestDocument(buf *bytes.Buffer, base int, data []byte, blockSize int) int {
+
+	addr := base + buf.Len()
+	docSize := len(data)
+
+	for first := true; first || len(data) != 0; first = false {
+
+		chunk := data
+		if len(chunk) > blockSize {
+			chunk = chunk[:blockSize]
+		}
+		data = data[len(chunk):]
+
+		next := containerEnd
+		if len(data) != 0 {
+			next = base + buf.Len() + blockHeaderSize + blockSize
+		}
+		size := docSize
+		if !first {
+			size = 0
+		}
+
+		fmt.Fprintf(buf, "\r\n%08x %08x %08x \r\n", size, blockSize, next)
+		buf.Write(chunk)
+		buf.Write(make([]byte, blockSize-len(chunk)))
+	}
+
+	return addr
+}
+
+// blocksSize returns size of chain of blocks for document
+func blocksSize(docSize int, blockSize int) int {
+	count := (docSize + blockSize - 1) / blockSize
+	if count == 0 {
+		count = 1
+	}
+	return count * (blockHeaderSize + blockSize)
+}
+
+// buildTestContainer builds 1C container with elements, data of elements is deflated if compress is set
+func buildTestContainer(elements []containerElement, compress bool, blockSize int) []byte {
+
+	tocSize := len(elements) * tocItemSize
+	base := containerHeaderSize + blocksSize(tocSize, blockSize)
+
+	var body bytes.Buffer
+	toc := make([]byte, 0, tocSize)
+
+	for _, element := range elements {
+
+		header := make([]byte, elemHeaderSize)
+		for _, char := range utf16.Encode([]rune(element.Name)) {
+			header = append(header, byte(char), byte(char>>8))
+		}
+		header = append(header, 0, 0, 0, 0)
+
+		data := element.Data
+		if compress {
+			var compressed bytes.Buffer
+			fw, _ := flate.NewWriter(&compressed, flate.BestCompression)
+			fw.Write(data)
+			fw.Close()
+			data = compressed.Bytes()
+		}
+
+		headerAddr := writeTestDocument(&body, base, header, blockSize)
+		dataAddr := writeTestDocument(&body, base, data, blockSize)
+
+		item := make([]byte, tocItemSize)
+		binary.LittleEndian.PutUint32(item, uint32(headerAddr))
+		binary.LittleEndian.PutUint32(item[4:], uint32(dataAddr))
+		binary.LittleEndian.PutUint32(item[8:], containerEnd)
+		toc = append(toc, item...)
+	}
+
+	var container bytes.Buffer
+	for _, value := range []uint32{containerEnd, uint32(blockSize), 0, 0} {
+		binary.Write(&container, binary.LittleEndian, value)
+	}
+	writeTestDocument(&container, 0, toc, blockSize)
+	container.Write(body.Bytes())
+
+	return container.Bytes()
+}
+
+func TestReadContainer(t *testing.T) {
+
+	text := bytes.Repeat([]byte("Процедура Тест()\r\nКонецПроцедуры\r\n"), 20)
+	nested := buildTestContainer([]containerElement{
+		{Name: "info", Data: []byte("{3,1,{0},\"\",0}")},
+		{Name: "text", Data: text},
+	}, false, 512)
+
+	elements := []containerElement{
+		{Name: "root", Data: []byte("{2,4f3c3ad2-1b6b-4a7e-8a8c-5f7c1d1e2f30,}")},
+		{Name: "4f3c3ad2-1b6b-4a7e-8a8c-5f7c1d1e2f30.0", Data: nested},
+		{Name: "Пустой", Data: []byte{}},
+	}
+
+	for _, compress := range []bool{true, false} {
+
+		// small blocks make chains of blocks
+		data := buildTestContainer(elements, compress, 64)
+		assert.True(t, isContainer(data))
+
+		readElements, err := readContainer(data, compress)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		if !assert.Equal(t, len(elements), len(readElements)) {
+			continue
+		}
+		for idx, element := range elements {
+			assert.Equal(t, element.Name, readElements[idx].Name)
+			assert.Equal(t, len(element.Data), len(readElements[idx].Data), element.Name)
+		}
+
+		nestedElements, err := readContainer(readElements[1].Data, false)
+		assert.NoError(t, err)
+		if assert.Equal(t, 2, len(nestedElements)) {
+			assert.Equal(t, "text", nestedElements[1].Name)
+			assert.Equal(t, text, nestedElements[1].Data)
+		}
+	}
+
+	_, err := readContainer([]byte("broken"), true)
+	assert.Error(t, err)
+
+	// truncated container
+	data := buildTestContainer(elements, true, 64)
+	_, err = readContainer(data[:len(data)/2], true)
+	assert.Error(t, err)
+}
+
+func TestContainerElement(t *testing.T) {
+
+	data := buildTestContainer([]containerElement{
+		{Name: "root", Data: []byte("{2,4f3c3ad2-1b6b-4a7e-8a8c-5f7c1d1e2f30,}")},
+		{Name: "broken", Data: []byte("not deflated")},
+	}, false, 64)
+
+	// table of contents is read, elements are inflated on demand
+	c, err := openContainer(bytes.NewReader(data), len(data), true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"root", "broken"}, c.names)
+
+	_, ok, err := c.element("unknown")
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	_, _, err = c.element("broken")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't inflate element broken")
+	}
+
+	_, err = readContainer(data, true)
+	assert.Error(t, err)
+}
+
+func TestReadDocumentMalformed(t *testing.T) {
+
+	header := make([]byte, containerHeaderSize)
+	testTable := []struct {
+		block          string
+		expectedString string
+	}{
+		// empty block points to itself
+		{fmt.Sprintf("\r\n%08x %08x %08x \r\n", 10, 0, containerHeaderSize), "empty block"},
+		{fmt.Sprintf("\r\n%08x %08x %08x \r\n", 10, 4, containerHeaderSize) + "abcd", "loops"},
+		{fmt.Sprintf("\r\n%08x %08x %08x \r\n", 0xfffffff0, 4, containerEnd) + "abcd", "greater than container"},
+	}
+
+	for _, testCase := range testTable {
+		data := append(append([]byte{}, header...), []byte(testCase.block)...)
+		c := &container{reader: bytes.NewReader(data), size: len(data)}
+		_, err := c.readDocument(containerHeaderSize)
+		if assert.Error(t, err, testCase.expectedString) {
+			assert.Contains(t, err.Error(), testCase.expectedString)
+		}
+	}
+}
+
+func TestParseBraces(t *testing.T) {
+
+	node, err := parseBraces("\uFEFF{1,\r\n{0,{0,0,5f7c1d1e}, \"Имя \"\"объекта\"\"\",{1,\"ru\",\"Синоним\"},\"\"},{}}")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, node.IsList)
+	assert.Equal(t, 3, len(node.Items))
+	assert.Equal(t, "1", node.Items[0].Value)
+	assert.True(t, node.Items[1].Items[2].IsString)
+	assert.Equal(t, "Имя \"объекта\"", node.Items[1].Items[2].Value)
+	assert.Equal(t, 0, len(node.Items[2].Items))
+
+	name, synonyms, ok := node.objectHeader("5f7c1d1e")
+	assert.True(t, ok)
+	assert.Equal(t, "Имя \"объекта\"", name)
+	assert.Equal(t, []string{"Синоним"}, synonyms)
+
+	for _, text := range []string{"{1,2", "{1,\"text}", "{1,\"a\"b}", ""} {
+		_, err = parseBraces(text)
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// dumpInfoMetadata is a metadata item of ConfigDumpInfo.xml like <Metadata name="Catalog.Справочник1" id="..."/>
+type dumpInfoMetadata struct {
+	Name string `xml:"name,attr"`
+	ID   string `xml:"id,attr"`
+}
+
+// dumpInfo is a structure for unmarshal ConfigDumpInfo.xml file
+type dumpInfo struct {
+	Format   string             `xml:"format,attr"`
+	Version  string             `xml:"version,attr"`
+	Metadata []dumpInfoMetadata `xml:"ConfigVersions>Metadata"`
+}
+
+// readDumpInfo reads and unmarshal ConfigDumpInfo.xml file
+func readDumpInfo(files fileSystem, filename string) (*dumpInfo, error) {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	di := &dumpInfo{}
+	err = xml.Unmarshal(byteValue, di)
+	if err != nil {
+		return nil, err
+	}
+
+	return di, nil
+}
+
+// getMetadataNamesByID returns full names of top level metadata objects by their ids from ConfigDumpInfo.xml,
+// file is read only once
+func (f *Finder) getMetadataNamesByID() map[string]string {
+
+	if f.metadataNamesByID != nil {
+		return f.metadataNamesByID
+	}
+
+	f.metadataNamesByID = make(map[string]string)
+
+	di, err := readDumpInfo(f.files, path.Join(f.srcdir, "ConfigDumpInfo.xml"))
+	if err != nil {
+		if f.Logging {
+			f.Logger.Printf("Не удалось прочитать ConfigDumpInfo.xml: %s", err)
+		}
+		return f.metadataNamesByID
+	}
+
+	for _, md := range di.Metadata {
+		// only objects like "Catalog.Справочник1" without nested forms, modules and etc.
+		if strings.Count(md.Name, ".") != 1 {
+			continue
+		}
+		f.metadataNamesByID[strings.ToLower(md.ID)] = md.Name
+	}
+
+	return f.metadataNamesByID
+}
+
+// warnDanglingID prints warning about id of subsystem content which is not resolved,
+// warning is printed once per run though subsystems are read several times
+func (f *Finder) warnDanglingID(id string, filename string) {
+
+	if f.danglingIDs == nil {
+		f.danglingIDs = make(map[string]bool)
+	}
+
+	key := filename + "\x00" + strings.ToLower(id)
+	if f.danglingIDs[key] {
+		return
+	}
+	f.danglingIDs[key] = true
+
+	fmt.Fprintf(os.Stderr, "WARN\tНе найден объект метаданных по ссылке %s в подсистеме %s\n", id, filename)
+}
+
+// resolveMetadataID returns full name of metadata object by id
+func (f *Finder) resolveMetadataID(id string) (string, bool) {
+	name, ok := f.getMetadataNamesByID()[strings.ToLower(id)]
+	return name, ok
+}
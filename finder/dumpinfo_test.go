@@ -0,0 +1,70 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var typicalSubsystemFilePath = path.Join(AbsPathTestSrcFolder, "Subsystems/ТиповыеОбъекты.xml")
+
+func TestReadDumpInfo(t *testing.T) {
+	di, err := readDumpInfo(osFiles, path.Join(AbsPathTestSrcFolder, "ConfigDumpInfo.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Catalog.Справочник1", di.Metadata[0].Name)
+	assert.Equal(t, "a2528cb0-7cc6-494b-a6be-cccd52c91ac1", di.Metadata[0].ID)
+}
+
+func TestResolveMetadataID(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+
+	name, ok := fndr.resolveMetadataID("D9063EBD-8B1E-49EF-9CFD-F8BBDF81F661")
+	assert.True(t, ok)
+	assert.Equal(t, "Report.Отчет1", name)
+
+	// nested metadata like forms are not resolved
+	_, ok = fndr.resolveMetadataID("2c8a60c0-39f6-437e-821c-f0d67d7d6bc6")
+	assert.False(t, ok)
+
+	_, ok = fndr.resolveMetadataID("0f3c9a2e-5b7d-4e1a-8c6f-9d2b4a7e1c35")
+	assert.False(t, ok)
+}
+
+func TestGetObjectsNamesFromSubsystemByID(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	metadataNames := fndr.getObjectsNamesFromSubsystem(typicalSubsystemFilePath)
+	assert.Equal(t, []string{"Catalog.Справочник1", "Document.Документ1", "Report.Отчет1"}, metadataNames)
+
+	// without ConfigDumpInfo.xml all ids are dangling
+	fndr = NewFinder(path.Join(AbsPathTestSrcFolder, "Subsystems"), "")
+	assert.Equal(t, 0, len(fndr.getMetadataNamesByID()))
+}
+
+func TestWarnDanglingIDOnce(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	output, err := os.Create(path.Join(tempDir, "stderr"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	stderr := os.Stderr
+	os.Stderr = output
+	defer func() { os.Stderr = stderr }()
+
+	// subsystem is read for inclusions and again for exclusions and modules
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	fndr.getObjectsNamesFromSubsystem(typicalSubsystemFilePath)
+	fndr.getObjectsNamesFromSubsystem(typicalSubsystemFilePath)
+	output.Close()
+
+	data, err := ioutil.ReadFile(output.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "0f3c9a2e-5b7d-4e1a-8c6f-9d2b4a7e1c35"))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"strings"
+)
+
+// exclusionsKeywordLine is a placeholder of list of excluded files in sonar-project.properties
+const exclusionsKeywordLine = "$exclusions_line"
+
+// exclusionsKey is a key of list of excluded files in sonar-project.properties
+const exclusionsKey = "sonar.exclusions"
+
+// getExcludedFilesPaths returns output paths to module files of source root which are not in resolved scope,
+// compacted to globs of metadata objects and types where possible
+func (f *Finder) getExcludedFilesPaths() []string {
+
+	selected := make(map[string]bool)
+	for _, filePath := range f.getBslFilesPaths() {
+		selected[filePath] = true
+	}
+
+	var excludedPaths []string
+	for _, file := range f.getSliceFiles(f.srcdir, f.modulePatterns()...) {
+		filePath := f.outputPath(file)
+		if !selected[filePath] {
+			excludedPaths = append(excludedPaths, filePath)
+		}
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Количество bsl модулей, исключенных из анализа: %d", len(excludedPaths))
+	}
+
+	return f.compactPaths(excludedPaths)
+}
+
+// getAllExcludedFilesPaths returns merged paths to excluded module files of main and all additional source roots
+func (f *Finder) getAllExcludedFilesPaths() []string {
+	return f.getAllRootsPaths((*Finder).getExcludedFilesPaths)
+}
+
+// isPropertyLine checks that line of properties file sets value of key like "key=value" or "key: value"
+func isPropertyLine(line string, key string) bool {
+	line = strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(line, key) {
+		return false
+	}
+	rest := strings.TrimLeft(line[len(key):], " \t")
+	return strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":")
+}
+
+// isContinuedLine checks that value of property continues on the next line
+func isContinuedLine(line string) bool {
+	line = strings.TrimRight(line, "\r")
+	trimmed := strings.TrimRight(line, "\\")
+	return (len(line)-len(trimmed))%2 == 1
+}
+
+// setPropertiesKey replaces value of key with continuation lines in content of properties file
+// or appends key to the end of content
+func setPropertiesKey(content string, key string, value string) string {
+
+	lines := strings.Split(content, "\n")
+
+	for idx, line := range lines {
+
+		if !isPropertyLine(line, key) {
+			continue
+		}
+
+		// the last line of value
+		last := idx
+		for last < len(lines)-1 && isContinuedLine(lines[last]) {
+			last++
+		}
+
+		lineEnd := ""
+		if strings.HasSuffix(lines[last], "\r") {
+			lineEnd = "\r"
+		}
+
+		newLines := append([]string{}, lines[:idx]...)
+		newLines = append(newLines, key+"="+value+lineEnd)
+		newLines = append(newLines, lines[last+1:]...)
+
+		return strings.Join(newLines, "\n")
+	}
+
+	if len(content) != 0 && !strings.HasSuffix(content, "\n") {
+		content = content + "\n"
+	}
+
+	return content + key + "=" + value + "\n"
+}
+
+// setExclusions writes list of excluded files to placeholder or to key sonar.exclusions of properties file
+func (f *Finder) setExclusions(content string) string {
+
+	line := f.pathsLine(f.getAllExcludedFilesPaths())
+
+	if strings.Contains(content, exclusionsKeywordLine) {
+		return strings.Replace(content, exclusionsKeywordLine, line, -1)
+	}
+
+	return setPropertiesKey(content, exclusionsKey, line)
+}
@@ -0,0 +1,94 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExcludedFilesPaths(t *testing.T) {
+
+	for _, srcdir := range []string{AbsPathTestSrcFolder, AbsPathTestEDTFolder} {
+
+		fndr := NewFinder(srcdir, "рн_")
+		selectedPaths := fndr.getBslFilesPaths()
+		excludedPaths := expandCompactedPaths(t, srcdir, fndr.getExcludedFilesPaths())
+
+		// excluded and selected files are all module files of folder
+		allPaths := testBslFiles(t, srcdir)
+		for _, filePath := range selectedPaths {
+			assert.NotContains(t, excludedPaths, filePath)
+		}
+		mergedPaths := append(append([]string{}, selectedPaths...), excludedPaths...)
+		sort.Strings(mergedPaths)
+		assert.Equal(t, allPaths, mergedPaths, srcdir)
+	}
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_ пс_")
+	excludedPaths := fndr.getExcludedFilesPaths()
+	assert.Contains(t, excludedPaths, "Catalogs/Справочник1/**/*.bsl")
+	assert.Contains(t, excludedPaths, "Ext/SessionModule.bsl")
+
+	// modules of configuration are not excluded if they are selected
+	fndr.ConfigModules = []string{ConfigModulesAll}
+	assert.NotContains(t, fndr.getExcludedFilesPaths(), "Ext/SessionModule.bsl")
+}
+
+func TestSetPropertiesKey(t *testing.T) {
+	testTable := []struct {
+		content         string
+		expectedContent string
+	}{
+		{"sonar.sources=src\n", "sonar.sources=src\nsonar.exclusions=a, b\n"},
+		{"sonar.sources=src", "sonar.sources=src\nsonar.exclusions=a, b\n"},
+		{"", "sonar.exclusions=a, b\n"},
+		{"sonar.exclusions=x\nsonar.sources=src\n", "sonar.exclusions=a, b\nsonar.sources=src\n"},
+		{"sonar.exclusions = x, \\\n  y, \\\n  z\nsonar.sources=src\n", "sonar.exclusions=a, b\nsonar.sources=src\n"},
+		{"# sonar.exclusions=x\r\nsonar.exclusions: x\r\n", "# sonar.exclusions=x\r\nsonar.exclusions=a, b\r\n"},
+		{"sonar.exclusionsExtra=x\n", "sonar.exclusionsExtra=x\nsonar.exclusions=a, b\n"},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedContent, setPropertiesKey(testCase.content, exclusionsKey, "a, b"), testCase.content)
+	}
+}
+
+func TestWriteExclusionsToFile(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	testTable := []struct {
+		content        string
+		expectedPrefix string
+		hasInclusions  bool
+	}{
+		{"sonar.inclusions=$inclusions_line\nsonar.exclusions=$exclusions_line\n", "sonar.exclusions=Catalogs/Справочник1/**/*.bsl", true},
+		{"sonar.sources=src/cf\n", "sonar.exclusions=Catalogs/Справочник1/**/*.bsl", false},
+	}
+
+	for _, testCase := range testTable {
+
+		sfile := path.Join(tempDir, "sonar-project.properties")
+		assert.NoError(t, ioutil.WriteFile(sfile, []byte(testCase.content), 0644))
+
+		fndr := NewFinder(AbsPathTestSrcFolder, "рн_ пс_")
+		fndr.Sfile = sfile
+		fndr.Exclusions = true
+		fndr.writeBslLineToFile()
+
+		data, err := ioutil.ReadFile(sfile)
+		assert.NoError(t, err)
+		content := string(data)
+
+		assert.NotContains(t, content, exclusionsKeywordLine)
+		assert.Contains(t, content, testCase.expectedPrefix)
+		assert.Equal(t, testCase.hasInclusions, strings.Contains(content, "sonar.inclusions=Catalogs/Справочник10/Ext/ManagerModule.bsl"))
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// objectBelongingAdopted is a value of property ObjectBelonging of objects adopted by configuration extension
+const objectBelongingAdopted = "Adopted"
+
+// metadataObject is a structure for unmarshal xml file of metadata object
+type metadataObject struct {
+	Object struct {
+		ObjectBelonging string `xml:"Properties>ObjectBelonging"`
+	} `xml:",any"`
+}
+
+// edtMetadataObject is a structure for unmarshal mdo file of metadata object of 1C:EDT project
+type edtMetadataObject struct {
+	ObjectBelonging string `xml:"objectBelonging"`
+}
+
+// readObjectBelonging reads value of property ObjectBelonging from xml or mdo file of metadata object
+func readObjectBelonging(files fileSystem, filename string) (string, error) {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if path.Ext(filename) == ".mdo" {
+		eo := &edtMetadataObject{}
+		if err = xml.Unmarshal(byteValue, eo); err != nil {
+			return "", err
+		}
+		return eo.ObjectBelonging, nil
+	}
+
+	o := &metadataObject{}
+	if err = xml.Unmarshal(byteValue, o); err != nil {
+		return "", err
+	}
+
+	return o.Object.ObjectBelonging, nil
+}
+
+// IsExtension is the method for check that sources are a dump of configuration extension
+func (f *Finder) IsExtension() bool {
+	c, err := readConfiguration(f.files, f.configurationFilePath())
+	if err != nil {
+		return false
+	}
+	return c.ObjectBelonging == objectBelongingAdopted
+}
+
+// extensionNamePrefix returns prefix of names of own objects of configuration extension
+func (f *Finder) extensionNamePrefix() string {
+	c, err := readConfiguration(f.files, f.configurationFilePath())
+	if err != nil {
+		println(err.Error())
+		return ""
+	}
+	return c.NamePrefix
+}
+
+// parsePhrases returns parse phrases, in extension mode name prefix of extension is used by default
+func (f *Finder) parsePhrases() string {
+
+	if !f.Extension || len(strings.TrimSpace(f.phrases)) != 0 {
+		return f.phrases
+	}
+
+	prefix := f.extensionNamePrefix()
+	if len(prefix) == 0 {
+		return ""
+	}
+
+	// phrase must match names started with prefix in any matching mode
+	switch f.Match {
+	case MatchGlob:
+		return prefix + "*"
+	case MatchRegexp:
+		return "^" + regexp.QuoteMeta(prefix)
+	default:
+		return prefix
+	}
+}
+
+// isAdoptedSubsystem checks that subsystem is adopted by extension from extended configuration
+func isAdoptedSubsystem(files fileSystem, filename string) bool {
+	s, err := readSubsystem(files, filename)
+	if err != nil {
+		return false
+	}
+	return s.ObjectBelonging == objectBelongingAdopted
+}
+
+// excludeAdoptedSubsystems leaves only own subsystems of extension
+func (f *Finder) excludeAdoptedSubsystems(subsystemsFilesPaths []string) []string {
+
+	var ownPaths []string
+
+	for _, sPath := range subsystemsFilesPaths {
+		if isAdoptedSubsystem(f.files, sPath) {
+			if f.Logging {
+				f.Logger.Printf("Заимствованная подсистема исключена из анализа: %s", sPath)
+			}
+			continue
+		}
+		ownPaths = append(ownPaths, sPath)
+	}
+
+	return ownPaths
+}
+
+// isAdoptedObject checks that metadata object is adopted by extension from extended configuration
+func (f *Finder) isAdoptedObject(relPath string) bool {
+	objectBelonging, err := readObjectBelonging(f.files, f.metadataFilePath(relPath))
+	if err != nil {
+		return false
+	}
+	return objectBelonging == objectBelongingAdopted
+}
+
+// hasCode checks that module contains something besides empty lines and comments
+func hasCode(files fileSystem, filename string) bool {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(byteValue), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF"))
+		if len(line) != 0 && !strings.HasPrefix(line, "//") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterModulesWithCode leaves only modules which contain code
+func filterModulesWithCode(files fileSystem, filesPaths []string) []string {
+
+	var filteredPaths []string
+
+	for _, filePath := range filesPaths {
+		if hasCode(files, filePath) {
+			filteredPaths = append(filteredPaths, filePath)
+		}
+	}
+
+	return filteredPaths
+}
@@ -0,0 +1,126 @@
+package finder
+
+import (
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var AbsPathTestExtensionFolder, _ = filepath.Abs("../tests/test_cfe")
+
+func TestIsExtension(t *testing.T) {
+	assert.True(t, NewFinder(AbsPathTestExtensionFolder, "").IsExtension())
+	assert.False(t, NewFinder(AbsPathTestSrcFolder, "").IsExtension())
+	assert.False(t, NewFinder(AbsPathTestEDTFolder, "").IsExtension())
+	assert.False(t, NewFinder(path.Join(AbsPathTestExtensionFolder, "Catalogs"), "").IsExtension())
+}
+
+func TestExtensionParsePhrases(t *testing.T) {
+	testTable := []struct {
+		phrases         string
+		match           string
+		extension       bool
+		expectedPhrases string
+	}{
+		{"", MatchPrefix, false, ""},
+		{"", MatchPrefix, true, "Расш1_"},
+		{"", MatchGlob, true, "Расш1_*"},
+		{"", MatchRegexp, true, "^Расш1_"},
+		{"рн_", MatchPrefix, true, "рн_"},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestExtensionFolder, testCase.phrases)
+		fndr.Match = testCase.match
+		fndr.Extension = testCase.extension
+		assert.Equal(t, testCase.expectedPhrases, fndr.parsePhrases())
+	}
+}
+
+func TestReadObjectBelonging(t *testing.T) {
+	objectBelonging, err := readObjectBelonging(osFiles, path.Join(AbsPathTestExtensionFolder, "Catalogs/Справочник1.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, objectBelongingAdopted, objectBelonging)
+
+	objectBelonging, err = readObjectBelonging(osFiles, path.Join(AbsPathTestExtensionFolder, "Catalogs/Расш1_Справочник.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", objectBelonging)
+
+	_, err = readObjectBelonging(osFiles, path.Join(AbsPathTestExtensionFolder, "Catalogs/Справочник2.xml"))
+	assert.Error(t, err)
+}
+
+func TestHasCode(t *testing.T) {
+	assert.True(t, hasCode(osFiles, path.Join(AbsPathTestExtensionFolder, "Catalogs/Справочник1/Ext/ObjectModule.bsl")))
+	assert.False(t, hasCode(osFiles, path.Join(AbsPathTestExtensionFolder, "Catalogs/Справочник1/Forms/ФормаЭлемента/Ext/Form/Module.bsl")))
+	assert.False(t, hasCode(osFiles, path.Join(AbsPathTestExtensionFolder, "Documents/Документ1/Ext/ManagerModule.bsl")))
+}
+
+func TestExtensionBslFilesPaths(t *testing.T) {
+	testTable := []struct {
+		phrases        string
+		match          string
+		scope          string
+		extension      bool
+		excludeAdopted bool
+		expectedPaths  []string
+	}{
+		{
+			"", MatchPrefix, ScopeSubsystems, true, false,
+			[]string{
+				"Catalogs/Расш1_Справочник/Ext/ManagerModule.bsl",
+				"Catalogs/Расш1_Справочник/Ext/ObjectModule.bsl",
+				"Catalogs/Справочник1/Ext/ObjectModule.bsl",
+				"Catalogs/Справочник1/Forms/ФормаЭлемента/Ext/Form/Module.bsl",
+				"Documents/Документ1/Ext/ManagerModule.bsl",
+			},
+		},
+		{
+			"", MatchPrefix, ScopeSubsystems, true, true,
+			[]string{
+				"Catalogs/Расш1_Справочник/Ext/ManagerModule.bsl",
+				"Catalogs/Расш1_Справочник/Ext/ObjectModule.bsl",
+				"Catalogs/Справочник1/Ext/ObjectModule.bsl",
+			},
+		},
+		{
+			"", MatchPrefix, ScopeNames, true, false,
+			[]string{
+				"Catalogs/Расш1_Справочник/Ext/ManagerModule.bsl",
+				"Catalogs/Расш1_Справочник/Ext/ObjectModule.bsl",
+				"CommonModules/Расш1_ОбщийМодуль/Ext/Module.bsl",
+			},
+		},
+		{
+			// adopted subsystem is skipped in extension mode
+			"рн_", MatchPrefix, ScopeSubsystems, true, false,
+			nil,
+		},
+		{
+			"рн_", MatchPrefix, ScopeSubsystems, false, false,
+			[]string{
+				"Catalogs/Справочник1/Ext/ObjectModule.bsl",
+				"Catalogs/Справочник1/Forms/ФормаЭлемента/Ext/Form/Module.bsl",
+			},
+		},
+		{
+			"*", MatchGlob, ScopeSubsystems, true, true,
+			[]string{
+				"Catalogs/Расш1_Справочник/Ext/ManagerModule.bsl",
+				"Catalogs/Расш1_Справочник/Ext/ObjectModule.bsl",
+				"Catalogs/Справочник1/Ext/ObjectModule.bsl",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestExtensionFolder, testCase.phrases)
+		fndr.Match = testCase.match
+		fndr.Scope = testCase.scope
+		fndr.Extension = testCase.extension
+		fndr.ExcludeAdopted = testCase.excludeAdopted
+		assert.Equal(t, testCase.expectedPaths, fndr.getBslFilesPaths(), testCase.phrases)
+	}
+}
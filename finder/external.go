@@ -0,0 +1,128 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"path"
+	"strings"
+)
+
+// ExternalTypes is a registry of classes of external data processors and reports
+var ExternalTypes = []MetadataType{
+	{"ExternalDataProcessor", "ВнешняяОбработка", "", []ModuleKind{ObjectModule, FormModule}},
+	{"ExternalReport", "ВнешнийОтчет", "", []ModuleKind{ObjectModule, FormModule}},
+}
+
+// externalObject is a structure for unmarshal root xml file of external data processor or report
+type externalObject struct {
+	Object struct {
+		XMLName xml.Name
+		Name    string `xml:"Properties>Name"`
+	} `xml:",any"`
+}
+
+// readExternalObjectName reads full name of external data processor or report like "ExternalReport.Отчет"
+func readExternalObjectName(files fileSystem, filename string) (string, bool) {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return "", false
+	}
+
+	o := &externalObject{}
+	if err = xml.Unmarshal(byteValue, o); err != nil {
+		return "", false
+	}
+
+	for _, mt := range ExternalTypes {
+		if o.Object.XMLName.Local == mt.Name && len(o.Object.Name) != 0 {
+			return mt.Name + "." + o.Object.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// getExternalObjectsNames returns full names of all external data processors and reports in folder
+func getExternalObjectsNames(files fileSystem, srcdir string) []string {
+
+	var objectsNames []string
+
+	xmlFiles, _ := files.Glob(path.Join(srcdir, "*.xml"))
+	for _, xmlFile := range xmlFiles {
+		if name, ok := readExternalObjectName(files, xmlFile); ok {
+			objectsNames = append(objectsNames, name)
+		}
+	}
+
+	return objectsNames
+}
+
+// getObjectsNamesFromExternal returns full names of external data processors and reports which names match phrases
+func (f *Finder) getObjectsNamesFromExternal(phrases []string, exclude []string) []string {
+
+	var objectsNames []string
+
+	if len(phrases) == 0 {
+		return []string{}
+	}
+
+	for _, fullName := range getExternalObjectsNames(f.files, f.srcdir) {
+		_, name, _ := splitMetadataName(fullName)
+		if !matchAnyPhrase(f.Match, phrases, name) || matchAnyPhrase(f.Match, exclude, name) {
+			continue
+		}
+		objectsNames = append(objectsNames, fullName)
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Найдено внешних обработок и отчетов по именам: %d", len(objectsNames))
+	}
+
+	return objectsNames
+}
+
+// qualifyExternalNames adds class to names of external data processors and reports from explicit list
+func (f *Finder) qualifyExternalNames(names []string) []string {
+
+	fullNames := make(map[string]string)
+	for _, fullName := range getExternalObjectsNames(f.files, f.srcdir) {
+		_, name, _ := splitMetadataName(fullName)
+		fullNames[name] = fullName
+	}
+
+	var qualifiedNames []string
+
+	for _, name := range names {
+		if strings.Contains(name, ".") {
+			qualifiedNames = append(qualifiedNames, name)
+			continue
+		}
+		fullName, ok := fullNames[name]
+		if !ok {
+			if f.Logging {
+				f.Logger.Printf("Внешняя обработка или отчет не найдены: %s", name)
+			}
+			continue
+		}
+		qualifiedNames = append(qualifiedNames, fullName)
+	}
+
+	return qualifiedNames
+}
@@ -0,0 +1,105 @@
+package finder
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var AbsPathTestExternalFolder, _ = filepath.Abs("../tests/test_epf")
+
+func TestExternalLayout(t *testing.T) {
+	assert.Equal(t, LayoutExternal, detectLayout(osFiles, AbsPathTestExternalFolder))
+
+	mt, ok := LookupMetadataType("ВнешняяОбработка")
+	if assert.True(t, ok) {
+		assert.Equal(t, "ExternalDataProcessor", mt.Name)
+	}
+
+	relPath, ok := metadataRelPath("ExternalReport.рн_ОтчетПродажи")
+	assert.True(t, ok)
+	assert.Equal(t, "рн_ОтчетПродажи", relPath)
+}
+
+func TestReadExternalObjectName(t *testing.T) {
+	testTable := []struct {
+		filename     string
+		expectedName string
+		expectedOk   bool
+	}{
+		{"рн_ЗагрузкаДанных.xml", "ExternalDataProcessor.рн_ЗагрузкаДанных", true},
+		{"рн_ОтчетПродажи.xml", "ExternalReport.рн_ОтчетПродажи", true},
+		{"рн_ЗагрузкаДанных/Forms/Форма.xml", "", false},
+		{"Нет.xml", "", false},
+	}
+
+	for _, testCase := range testTable {
+		name, ok := readExternalObjectName(osFiles, path.Join(AbsPathTestExternalFolder, testCase.filename))
+		assert.Equal(t, testCase.expectedOk, ok, testCase.filename)
+		assert.Equal(t, testCase.expectedName, name, testCase.filename)
+	}
+
+	assert.Equal(t, 3, len(getExternalObjectsNames(osFiles, AbsPathTestExternalFolder)))
+	assert.Equal(t, 0, len(getExternalObjectsNames(osFiles, AbsPathTestSrcFolder)))
+}
+
+func TestExternalBslFilesPaths(t *testing.T) {
+	testTable := []struct {
+		phrases       string
+		match         string
+		expectedPaths []string
+	}{
+		{
+			"рн_", MatchPrefix,
+			[]string{
+				"рн_ЗагрузкаДанных/Ext/ObjectModule.bsl",
+				"рн_ЗагрузкаДанных/Forms/Форма/Ext/Form/Module.bsl",
+				"рн_ОтчетПродажи/Ext/ObjectModule.bsl",
+			},
+		},
+		{
+			"рн_ !рн_Отчет", MatchPrefix,
+			[]string{
+				"рн_ЗагрузкаДанных/Ext/ObjectModule.bsl",
+				"рн_ЗагрузкаДанных/Forms/Форма/Ext/Form/Module.bsl",
+			},
+		},
+		{
+			"Выгр*", MatchGlob,
+			[]string{
+				"Выгрузка/Forms/Форма/Ext/Form/Module.bsl",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestExternalFolder, testCase.phrases)
+		fndr.Match = testCase.match
+		assert.Equal(t, testCase.expectedPaths, fndr.getBslFilesPaths(), testCase.phrases)
+	}
+}
+
+func TestExternalObjectsList(t *testing.T) {
+
+	listFile, err := os.CreateTemp("", "external-objects-list")
+	assert.NoError(t, err)
+	defer os.Remove(listFile.Name())
+	_, err = listFile.WriteString("Выгрузка\nExternalReport.рн_ОтчетПродажи\nНет\n")
+	assert.NoError(t, err)
+	listFile.Close()
+
+	fndr := NewFinder(AbsPathTestExternalFolder, "")
+	fndr.ObjectsFile = listFile.Name()
+	fndr.ModuleKinds = []ModuleKind{ObjectModule, FormModule}
+	assert.Equal(t, []string{
+		"ExternalDataProcessor.Выгрузка",
+		"ExternalReport.рн_ОтчетПродажи",
+	}, fndr.getSliceMetadataName())
+	assert.Equal(t, []string{
+		"Выгрузка/Forms/Форма/Ext/Form/Module.bsl",
+		"рн_ОтчетПродажи/Ext/ObjectModule.bsl",
+	}, fndr.getBslFilesPaths())
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// fileSystem is an access to files of sources placed in folder or in archive
+type fileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.FileInfo, error)
+	Walk(root string, fn filepath.WalkFunc) error
+	Glob(pattern string) ([]string, error)
+}
+
+// osFileSystem is an access to files of sources in folder on disk
+type osFileSystem struct{}
+
+// osFiles is a default access to files on disk
+var osFiles fileSystem = osFileSystem{}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]fs.FileInfo, error) {
+	return ioutil.ReadDir(name)
+}
+
+func (osFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (osFileSystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// newFileSystem returns access to files of sources by path to folder or archive
+func newFileSystem(srcdir string) (fileSystem, error) {
+	if IsArchive(srcdir) {
+		return openArchive(srcdir)
+	}
+	return osFiles, nil
+}
+
+// exists checks that file or folder exists
+func exists(files fileSystem, name string) bool {
+	_, err := files.Stat(name)
+	return err == nil
+}
+
+// outputPath returns path to file of sources for output, relative to srcdir or absolute,
+// for archive absolute path is computed as if archive is extracted to base folder
+func (f *Finder) outputPath(filePath string) string {
+
+	relPath, _ := filepath.Rel(f.srcdir, filePath)
+	if !f.Abspath {
+		return relPath
+	}
+
+	if afs, ok := f.files.(*archiveFileSystem); ok {
+		base := f.Base
+		if len(base) == 0 {
+			base = ArchiveBase(f.srcdir)
+		}
+		return path.Join(filepath.ToSlash(base), afs.root, filepath.ToSlash(relPath))
+	}
+
+	return filePath
+}
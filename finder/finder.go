@@ -19,7 +19,6 @@ package finder
 
 import (
 	"bytes"
-	"encoding/xml"
 	"fmt"
 	"github.com/thoas/go-funk"
 	"io/fs"
@@ -27,7 +26,6 @@ import (
 	"log"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -42,45 +40,96 @@ type Finder struct {
 	Sfile              string `json:"path to sonar-project.properties"`
 	Abspath            bool
 	Logging            bool
-	Unicode            bool `json:"convert Cyrillic symbols to unicode"`
-	Generate           bool `json:"generate out data to template"`
+	Unicode            bool         `json:"convert Cyrillic symbols to unicode"`
+	Generate           bool         `json:"generate out data to template"`
+	Recursive          bool         `json:"include child subsystems regardless of their names"`
+	ExcludeChildren    bool         `json:"exclude child subsystems of excluded subsystems"`
+	Match              string       `json:"mode of matching parse phrases with subsystems"`
+	ObjectsFile        string       `json:"path to file with list of metadata objects"`
+	Scope              string       `json:"scope of metadata objects selection"`
+	Format             string       `json:"format of output data"`
+	ConfigModules      []string     `json:"names of configuration modules to include"`
+	ModuleKinds        []ModuleKind `json:"kinds of modules of metadata objects to include"`
+	Layout             string       `json:"layout of configuration sources"`
+	Extension          bool         `json:"select own subsystems and objects of configuration extension"`
+	ExcludeAdopted     bool         `json:"exclude modules of adopted objects without extension code"`
+	Prefix             string       `json:"prefix of relative output paths"`
+	Roots              []Root       `json:"additional source roots"`
+	Extensions         []string     `json:"extensions of module files"`
+	Base               string       `json:"folder to which archive with sources is considered extracted"`
+	Compact            bool         `json:"collapse paths to globs of metadata objects and types"`
+	Exclusions         bool         `json:"output module files which are not in scope"`
+	Modules            bool         `json:"generate module of SonarQube project for each top level subsystem"`
+	MaxArgsLength      int          `json:"length of sonar-scanner arguments after which response file is used"`
+	ResponseFile       string       `json:"path to response file with long sonar-scanner arguments"`
 	keywordLine        string
 	rootSubsystemsPath string
+	metadataNamesByID  map[string]string
+	danglingIDs        map[string]bool
+	objectsNames       []string
+	subsystemsByName   map[string][]string
+	subsystemsPaths    []string
+	subsystemsByPath   map[string]*subsystem
+	files              fileSystem
+	filesErr           error
+	sources            map[string]*Finder
 	Logger             *log.Logger
 }
 
 // NewFinder is the method for create new finder structure
 func NewFinder(srcdir string, phrases string) *Finder {
+
+	// sources can be placed in folder or in archive
+	files, filesErr := newFileSystem(srcdir)
+	if filesErr != nil {
+		files = osFiles
+	}
+
 	finder := &Finder{
 		srcdir:             srcdir,
 		phrases:            phrases,
 		keywordLine:        "$inclusions_line",
 		rootSubsystemsPath: path.Join(srcdir, "Subsystems"),
+		Match:              MatchPrefix,
+		Scope:              ScopeSubsystems,
+		Format:             FormatText,
+		Layout:             detectLayout(files, srcdir),
+		Extensions:         DefaultExtensions,
+		MaxArgsLength:      DefaultMaxArgsLength,
+		ResponseFile:       DefaultResponseFile,
 		Logger:             log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime),
+		files:              files,
+		filesErr:           filesErr,
+		danglingIDs:        make(map[string]bool),
 	}
+	finder.sources = map[string]*Finder{srcdir: finder}
 
 	return finder
 }
 
+// SetPhrases sets parse phrases of finder got for sources by SourceFinder
+func (f *Finder) SetPhrases(phrases string) {
+	f.phrases = phrases
+}
+
 func (f *Finder) stringToUnicode(str string) string {
 	// Transform cyrillic symbols to unicode ascii
 	return strings.Trim(strconv.QuoteToASCII(str), "\"")
 }
 
-func (f *Finder) getSubsystemsFilesPaths() []string {
+func (f *Finder) getPrefixedSubsystemsFilesPaths(prfxs []string) []string {
 
 	var subsystemsFilesPaths []string
 
-	prfxs := strings.Split(f.phrases, " ")
 	for _, prfx := range prfxs {
 
 		sPattern := prfx + "*.xml"
 
-		err := filepath.Walk(f.rootSubsystemsPath, func(wpath string, info fs.FileInfo, err error) error {
+		err := f.files.Walk(f.rootSubsystemsPath, func(wpath string, info fs.FileInfo, err error) error {
 			if info == nil || !info.IsDir() {
 				return nil
 			}
-			sFiles, _ := filepath.Glob(path.Join(wpath, sPattern))
+			sFiles, _ := f.files.Glob(path.Join(wpath, sPattern))
 			subsystemsFilesPaths = append(subsystemsFilesPaths, sFiles...)
 
 			return nil
@@ -92,6 +141,42 @@ func (f *Finder) getSubsystemsFilesPaths() []string {
 
 	}
 
+	return subsystemsFilesPaths
+}
+
+func (f *Finder) getSubsystemsFilesPaths() []string {
+
+	var subsystemsFilesPaths []string
+
+	inclPhrases, exclPhrases := splitPhrases(f.parsePhrases())
+	selectors, prfxs := splitSelectors(inclPhrases)
+
+	// find subsystems by file name prefix or by name and synonym patterns
+	if f.Match == MatchGlob || f.Match == MatchRegexp || f.Layout == LayoutEDT {
+		subsystemsFilesPaths = f.getMatchedSubsystemsFilesPaths(prfxs)
+	} else {
+		subsystemsFilesPaths = f.getPrefixedSubsystemsFilesPaths(prfxs)
+	}
+
+	// find subsystems by hierarchical paths
+	subsystemsFilesPaths = append(subsystemsFilesPaths, f.getSelectedSubsystemsFilesPaths(selectors)...)
+
+	// add all descendants of found subsystems
+	if f.Recursive {
+		for _, sPath := range subsystemsFilesPaths {
+			subsystemsFilesPaths = append(subsystemsFilesPaths, f.getChildSubsystemsFilesPaths(sPath)...)
+		}
+	}
+	subsystemsFilesPaths = funk.UniqString(subsystemsFilesPaths)
+
+	// remove excluded subsystems
+	subsystemsFilesPaths = f.excludeSubsystems(subsystemsFilesPaths, exclPhrases)
+
+	// only own subsystems of configuration extension
+	if f.Extension {
+		subsystemsFilesPaths = f.excludeAdoptedSubsystems(subsystemsFilesPaths)
+	}
+
 	if f.Logging {
 		f.Logger.Printf(">>> Найдено подсистем для анализа: %d", len(subsystemsFilesPaths))
 	}
@@ -101,48 +186,35 @@ func (f *Finder) getSubsystemsFilesPaths() []string {
 
 func (f *Finder) getObjectsNamesFromSubsystem(filename string) []string {
 
-	// structure for unmarshal xml
-	type Metadata struct {
-		Names []string `xml:"Subsystem>Properties>Content>Item"`
-	}
-
 	// slice for collect all metadata names
 	var MetadataNames []string
 
-	// mask for exclusion deleted metadata
-	mask := "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+	// mask for metadata referenced by id
+	mask := "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"
 	re := regexp.MustCompile(mask)
 
-	// open xml file
-	xmlFile, err := os.Open(filename)
+	// read and unmarshal xml file
+	s, err := f.parsedSubsystem(filename)
 	if err != nil {
 		println(err.Error())
 		return []string{}
 	}
 
-	defer func(xmlFile *os.File) {
-		err := xmlFile.Close()
-		if err != nil {
-			println(err.Error())
+	// check metadata (not empty, resolve ids) and append to slice
+	for _, item := range s.Content {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
 		}
-	}(xmlFile)
-
-	// read content of xml file
-	byteValue, _ := ioutil.ReadAll(xmlFile)
-
-	// unmarshalling
-	m := Metadata{Names: []string{}}
-	err = xml.Unmarshal(byteValue, &m)
-	if err != nil {
-		println(err.Error())
-		return []string{}
-	}
-
-	// check metadata (not deleted or empty) and append to slice
-	for _, item := range m.Names {
-		if len(item) != 0 && !re.Match([]byte(item)) {
-			MetadataNames = append(MetadataNames, item)
+		if re.MatchString(item) {
+			name, ok := f.resolveMetadataID(item)
+			if !ok {
+				f.warnDanglingID(item, filename)
+				continue
+			}
+			item = name
 		}
+		MetadataNames = append(MetadataNames, item)
 	}
 
 	return MetadataNames
@@ -153,17 +225,56 @@ func (f *Finder) getSliceMetadataName() []string {
 	// slice for collect all metadata names
 	var SliceMetadataNames []string
 
-	// get subsystems
-	SubsystemsFilesPaths := f.getSubsystemsFilesPaths()
+	// matched subsystems and their hierarchical paths for each object of their content
+	f.subsystemsByName = make(map[string][]string)
+	f.subsystemsPaths = nil
 
-	// get bsl files by subsystems
-	for _, SubPath := range SubsystemsFilesPaths {
+	if f.Layout == LayoutExternal {
+
+		// get external data processors and reports by names
+		inclPhrases, exclPhrases := splitPhrases(f.parsePhrases())
+		_, prfxs := splitSelectors(inclPhrases)
+		_, exclPrfxs := splitSelectors(exclPhrases)
+		SliceMetadataNames = append(SliceMetadataNames, f.getObjectsNamesFromExternal(prfxs, exclPrfxs)...)
+
+	} else if f.Scope != ScopeNames {
+
+		// get subsystems
+		SubsystemsFilesPaths := f.getSubsystemsFilesPaths()
+		f.subsystemsPaths = SubsystemsFilesPaths
+
+		// get bsl files by subsystems
+		for _, SubPath := range SubsystemsFilesPaths {
+
+			if f.Logging {
+				f.Logger.Printf("%s", SubPath)
+			}
+			subsystemNames := f.getObjectsNamesFromSubsystem(SubPath)
+			SliceMetadataNames = append(SliceMetadataNames, subsystemNames...)
+
+			chain := f.subsystemHierarchicalPath(SubPath)
+			for _, name := range subsystemNames {
+				name = normalizeMetadataName(name)
+				f.subsystemsByName[name] = append(f.subsystemsByName[name], chain)
+			}
 
-		if f.Logging {
-			f.Logger.Printf("%s", SubPath)
 		}
-		SliceMetadataNames = append(SliceMetadataNames, f.getObjectsNamesFromSubsystem(SubPath)...)
+	}
 
+	if f.Layout != LayoutExternal && (f.Scope == ScopeNames || f.Scope == ScopeBoth) {
+
+		// get configuration objects by names
+		inclPhrases, exclPhrases := splitPhrases(f.parsePhrases())
+		_, prfxs := splitSelectors(inclPhrases)
+		_, exclPrfxs := splitSelectors(exclPhrases)
+		SliceMetadataNames = append(SliceMetadataNames, f.getObjectsNamesFromConfiguration(prfxs, exclPrfxs)...)
+	}
+
+	// add metadata names from explicit objects list
+	if f.Layout == LayoutExternal {
+		SliceMetadataNames = append(SliceMetadataNames, f.qualifyExternalNames(f.getObjectsNamesFromList())...)
+	} else {
+		SliceMetadataNames = append(SliceMetadataNames, f.getObjectsNamesFromList()...)
 	}
 
 	sort.Strings(SliceMetadataNames)
@@ -175,16 +286,18 @@ func (f *Finder) getSliceMetadataName() []string {
 	return funk.UniqString(SliceMetadataNames)
 }
 
-func (f *Finder) getSliceFiles(PathToFolder string, pattern string) []string {
+func (f *Finder) getSliceFiles(PathToFolder string, patterns ...string) []string {
 
 	var SliceFiles []string
 
-	err := filepath.Walk(PathToFolder, func(wpath string, info fs.FileInfo, err error) error {
+	err := f.files.Walk(PathToFolder, func(wpath string, info fs.FileInfo, err error) error {
 		if info == nil || !info.IsDir() {
 			return nil
 		}
-		sFiles, _ := filepath.Glob(path.Join(wpath, pattern))
-		SliceFiles = append(SliceFiles, sFiles...)
+		for _, pattern := range patterns {
+			sFiles, _ := f.files.Glob(path.Join(wpath, pattern))
+			SliceFiles = append(SliceFiles, sFiles...)
+		}
 
 		return nil
 	})
@@ -200,45 +313,66 @@ func (f *Finder) getBslFilesPaths() []string {
 
 	SliceMetadataName := f.getSliceMetadataName()
 
-	var SliceBslFilesPaths []string
+	// modules of configuration are first
+	SliceBslFilesPaths := f.getConfigurationModulesFilesPaths()
 
 	for _, MetadataName := range SliceMetadataName {
+		SliceBslFilesPaths = append(SliceBslFilesPaths, f.getObjectBslFilesPaths(MetadataName)...)
+	}
 
-		MetadataTypeName := MetadataName[:strings.Index(MetadataName, ".")] + "s"
-		MetadataOnlyName := MetadataName[strings.Index(MetadataName, ".")+1:]
-		MetadataRelPath := path.Join(MetadataTypeName, MetadataOnlyName)
-		PathToFolder := path.Join(f.srcdir, MetadataRelPath)
+	if f.Logging {
+		f.Logger.Printf(">>> Количество bsl модулей для проверки: %d", len(SliceBslFilesPaths))
+	}
 
-		// check folder exist
-		_, err := os.Stat(PathToFolder)
-		if os.IsNotExist(err) {
-			continue
-		}
+	return SliceBslFilesPaths
+}
 
-		// get slice of bsl files in folder
-		BslFiles := f.getSliceFiles(PathToFolder, "*.bsl")
+// getObjectBslFilesPaths returns output paths to bsl files of metadata object
+func (f *Finder) getObjectBslFilesPaths(MetadataName string) []string {
 
-		if !f.Abspath {
-			// transform path to bsl files without basepath
-			for idx, file := range BslFiles {
-				BslFiles[idx], _ = filepath.Rel(f.srcdir, file)
-			}
+	MetadataRelPath, ok := metadataRelPath(MetadataName)
+	if !ok {
+		if f.Logging {
+			f.Logger.Printf("Неизвестный тип объекта метаданных: %s", MetadataName)
 		}
+		return nil
+	}
+	PathToFolder := path.Join(f.srcdir, MetadataRelPath)
+
+	// check folder exist
+	_, err := f.files.Stat(PathToFolder)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	// get slice of module files in folder
+	BslFiles := f.getSliceFiles(PathToFolder, f.modulePatterns()...)
 
-		SliceBslFilesPaths = append(SliceBslFilesPaths, BslFiles...)
+	// leave only modules of selected kinds
+	BslFiles = f.filterModuleKinds(BslFiles)
 
+	// leave only modules with code of extension in adopted objects
+	if f.ExcludeAdopted && f.isAdoptedObject(MetadataRelPath) {
+		BslFiles = filterModulesWithCode(f.files, BslFiles)
+		if len(BslFiles) == 0 && f.Logging {
+			f.Logger.Printf("Заимствованный объект без кода расширения исключен из анализа: %s", MetadataName)
+		}
 	}
 
-	if f.Logging {
-		f.Logger.Printf(">>> Количество bsl модулей для проверки: %d", len(SliceBslFilesPaths))
+	// transform path to bsl files without basepath
+	for idx, file := range BslFiles {
+		BslFiles[idx] = f.outputPath(file)
 	}
 
-	return SliceBslFilesPaths
+	return BslFiles
 }
 
 func (f *Finder) getBslFilesLine() string {
+	return f.pathsLine(f.getAllBslFilesPaths())
+}
 
-	SliceBslFilesPaths := f.getBslFilesPaths()
+// pathsLine makes one line list of paths for properties file
+func (f *Finder) pathsLine(SliceBslFilesPaths []string) string {
 
 	// convert Cyrillic symbols to unicode ascii
 	if f.Unicode {
@@ -247,20 +381,7 @@ func (f *Finder) getBslFilesLine() string {
 		}
 	}
 
-	var LineBslFiles string
-
-	EndLine := ", \\\n"
-
-	// make one line list of bsl files
-	for _, BslFilePath := range SliceBslFilesPaths {
-		LineBslFiles = LineBslFiles + BslFilePath + EndLine
-	}
-
-	// eval last line end index without spec symbols
-	LastLineEndIndex := len(LineBslFiles) - len(EndLine)
-	LineBslFiles = LineBslFiles[:LastLineEndIndex]
-
-	return LineBslFiles
+	return strings.Join(SliceBslFilesPaths, ", \\\n")
 }
 
 func (f *Finder) writeBslLineToFile() {
@@ -300,6 +421,16 @@ func (f *Finder) writeBslLineToFile() {
 
 	}
 
+	// list of excluded files
+	if f.Exclusions {
+		spfContent = f.setExclusions(spfContent)
+	}
+
+	// modules of multi-module project by subsystems
+	if f.Modules {
+		spfContent = f.setModules(spfContent)
+	}
+
 	var writeErr error
 	// write sonar properties content to file
 
@@ -316,7 +447,10 @@ func (f *Finder) writeBslLineToFile() {
 
 func (f *Finder) writeBslLineToSTDOUT() {
 
-	LineBslFiles := f.getBslFilesPaths()
+	LineBslFiles := f.getAllBslFilesPaths()
+	if f.Exclusions {
+		LineBslFiles = f.getAllExcludedFilesPaths()
+	}
 
 	for idx := range LineBslFiles {
 		// convert Cyrillic symbols to unicode ascii and print
@@ -331,6 +465,18 @@ func (f *Finder) writeBslLineToSTDOUT() {
 // DataToSonarQube is a method for output data
 func (f *Finder) DataToSonarQube() {
 
+	f.checkPlatformVersion()
+
+	if f.Format == FormatJSON {
+		f.writeScopeToSTDOUT()
+		return
+	}
+
+	if f.Format == FormatArgs || f.Format == FormatCmd || f.Format == FormatDotenv {
+		f.writeScannerArgsToSTDOUT()
+		return
+	}
+
 	if len(f.Sfile) != 0 {
 		f.writeBslLineToFile()
 	} else {
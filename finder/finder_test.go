@@ -24,11 +24,18 @@ var AbsPathTestSonarUnicodeFile, _ = filepath.Abs("../tests/test-unicode-sonar-p
 
 var phrases = "рн_ пс_"
 var countSubsystemsFilesPaths = 7
+var countSubsystemsFilesPathsRecursive = 8
+var countChildSubsystemsFilesPaths = 3
+var CountGetListMetadataNameRecursive = 25
+var phrasesExclude = "рн_ !рн_Супер"
+var countSubsystemsFilesPathsExclude = 4
+var countSubsystemsFilesPathsExcludeChildren = 2
 var CountGetObjectsNamesFromSubsystem = 2
 var CountGetListMetadataName = 24
 var CountGetListBslFiles = 3
 var CountGetBslFilesPaths = 63
 var subsystemFilePath, _ = filepath.Abs(path.Join(AbsPathTestSrcFolder, "Subsystems/рн_Супер.xml"))
+var nestedSubsystemFilePath, _ = filepath.Abs(path.Join(AbsPathTestSrcFolder, "Subsystems/рн_Супер/Subsystems/рн_упс/Subsystems/общие_механизмы.xml"))
 var ObjectFolderPath, _ = filepath.Abs(path.Join(AbsPathTestSrcFolder, "Catalogs/Справочник8"))
 var pattern = "*.bsl"
 var CountLineBslFiles = 3873
@@ -41,6 +48,8 @@ type FinderTestSuite struct {
 	BaseFinderUnicodeStdOut  *Finder
 	BaseFinderFileOut        *Finder
 	BaseFinderUnicodeFileOut *Finder
+	BaseFinderRecursive      *Finder
+	BaseFinderExclude        *Finder
 	//BaseFinderStdOutVerbose *Finder
 	fsppContent  string // fixture-sonar-project.properties
 	fusppContent string // fixture-unicode-sonar-project.properties
@@ -99,6 +108,13 @@ func (suite *FinderTestSuite) SetupSuite() {
 	suite.BaseFinderUnicodeFileOut.Unicode = true
 	suite.BaseFinderUnicodeFileOut.Sfile = AbsPathTestSonarUnicodeFile
 
+	suite.BaseFinderRecursive = NewFinder(AbsPathTestSrcFolder, phrases)
+	suite.BaseFinderRecursive.Logging = true
+	suite.BaseFinderRecursive.Recursive = true
+
+	suite.BaseFinderExclude = NewFinder(AbsPathTestSrcFolder, phrasesExclude)
+	suite.BaseFinderExclude.Logging = true
+
 	// read fixture-sonar-project.properties file
 	fspp, _ := ioutil.ReadFile(AbsPathFixtureSonarFile)
 	// content of file
@@ -146,6 +162,96 @@ func (suite *FinderTestSuite) TestGetSubsystemsFilesPaths() {
 	suite.Equal(countSubsystemsFilesPaths, len(subsystemsFilesPaths))
 }
 
+func (suite *FinderTestSuite) TestGetSubsystemsFilesPathsRecursive() {
+	subsystemsFilesPaths := suite.BaseFinderRecursive.getSubsystemsFilesPaths()
+	suite.Equal(countSubsystemsFilesPathsRecursive, len(subsystemsFilesPaths))
+	suite.Contains(subsystemsFilesPaths, nestedSubsystemFilePath)
+}
+
+func (suite *FinderTestSuite) TestSplitPhrases() {
+	include, exclude := splitPhrases("рн_  пс_ !рн_Супер !")
+	suite.Equal([]string{"рн_", "пс_"}, include)
+	suite.Equal([]string{"рн_Супер"}, exclude)
+}
+
+func (suite *FinderTestSuite) TestGetSubsystemsFilesPathsExclude() {
+	suite.BaseFinderExclude.ExcludeChildren = false
+	subsystemsFilesPaths := suite.BaseFinderExclude.getSubsystemsFilesPaths()
+	suite.Equal(countSubsystemsFilesPathsExclude, len(subsystemsFilesPaths))
+	suite.NotContains(subsystemsFilesPaths, subsystemFilePath)
+
+	suite.BaseFinderExclude.ExcludeChildren = true
+	subsystemsFilesPaths = suite.BaseFinderExclude.getSubsystemsFilesPaths()
+	suite.Equal(countSubsystemsFilesPathsExcludeChildren, len(subsystemsFilesPaths))
+}
+
+func (suite *FinderTestSuite) TestGetSubsystemsFilesPathsMatch() {
+	testTable := []struct {
+		match         string
+		phrases       string
+		expectedCount int
+	}{
+		{MatchGlob, "Рн*", 5},
+		{MatchGlob, "*упс", 1},
+		{MatchGlob, "Рн* !*дубль", 3},
+		{MatchRegexp, "упс$", 1},
+		{MatchRegexp, "(?i)^типовые", 1},
+		{MatchRegexp, "^пс_ ^общие", 3},
+		{MatchPrefix, "Рн", 0},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, testCase.phrases)
+		fndr.Match = testCase.match
+		subsystemsFilesPaths := fndr.getSubsystemsFilesPaths()
+		suite.Equal(testCase.expectedCount, len(subsystemsFilesPaths), testCase.phrases)
+	}
+}
+
+func (suite *FinderTestSuite) TestGetSubsystemsFilesPathsSelector() {
+	testTable := []struct {
+		phrases       string
+		expectedCount int
+	}{
+		{"рн_Супер/рн_пип", 1},
+		{"рн_Супер/**", 4},
+		{"рн_Супер/рн_упс/**", 2},
+		{"рн_Супер/** !рн_Супер/рн_упс/**", 2},
+		{"рн_дубль/рн_поддубль пс_", 3},
+		{"рн_ !рн_дубль/рн_поддубль", 4},
+		{"рн_Супер/рн_нет", 0},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, testCase.phrases)
+		subsystemsFilesPaths := fndr.getSubsystemsFilesPaths()
+		suite.Equal(testCase.expectedCount, len(subsystemsFilesPaths), testCase.phrases)
+	}
+}
+
+func (suite *FinderTestSuite) TestResolvePathSelector() {
+	filename, withDescendants := suite.BaseFinder.resolvePathSelector("рн_Супер/рн_упс/**")
+	suite.Equal(path.Join(AbsPathTestSrcFolder, "Subsystems/рн_Супер/Subsystems/рн_упс.xml"), filename)
+	suite.True(withDescendants)
+
+	filename, withDescendants = suite.BaseFinder.resolvePathSelector("рн_дубль/")
+	suite.Equal(path.Join(AbsPathTestSrcFolder, "Subsystems/рн_дубль.xml"), filename)
+	suite.False(withDescendants)
+}
+
+func (suite *FinderTestSuite) TestReadSubsystem() {
+	s, err := readSubsystem(osFiles, subsystemFilePath)
+	suite.NoError(err)
+	suite.Equal("рн_Супер", s.Name)
+	suite.Equal([]string{"Рн супер"}, s.Synonyms)
+	suite.Equal([]string{"рн_пип", "рн_упс"}, s.Children)
+}
+
+func (suite *FinderTestSuite) TestGetChildSubsystemsFilesPaths() {
+	childFilesPaths := suite.BaseFinder.getChildSubsystemsFilesPaths(subsystemFilePath)
+	suite.Equal(countChildSubsystemsFilesPaths, len(childFilesPaths))
+}
+
 func (suite *FinderTestSuite) TestGetObjectsNamesFromSubsystem() {
 	metadataNames := suite.BaseFinder.getObjectsNamesFromSubsystem(subsystemFilePath)
 	suite.Equal(CountGetObjectsNamesFromSubsystem, len(metadataNames))
@@ -156,6 +262,12 @@ func (suite *FinderTestSuite) TestGetSliceMetadataName() {
 	suite.Equal(CountGetListMetadataName, len(sliceMetadataNames))
 }
 
+func (suite *FinderTestSuite) TestGetSliceMetadataNameRecursive() {
+	sliceMetadataNames := suite.BaseFinderRecursive.getSliceMetadataName()
+	suite.Equal(CountGetListMetadataNameRecursive, len(sliceMetadataNames))
+	suite.Contains(sliceMetadataNames, "Catalog.Справочник2")
+}
+
 func (suite *FinderTestSuite) TestGetSliceFiles() {
 	sliceFiles := suite.BaseFinder.getSliceFiles(ObjectFolderPath, pattern)
 	suite.Equal(CountGetListBslFiles, len(sliceFiles))
@@ -166,6 +278,25 @@ func (suite *FinderTestSuite) TestGetBslFilesPaths() {
 	suite.Equal(CountGetBslFilesPaths, len(sliceBslFilesPaths))
 }
 
+func (suite *FinderTestSuite) TestGetBslFilesPathsModuleKinds() {
+	testTable := []struct {
+		moduleKinds   []ModuleKind
+		expectedCount int
+	}{
+		{[]ModuleKind{ObjectModule}, 24},
+		{[]ModuleKind{ObjectModule, ManagerModule}, 48},
+		{[]ModuleKind{FormModule}, 15},
+		{[]ModuleKind{CommandModule}, 0},
+		{nil, CountGetBslFilesPaths},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, phrases)
+		fndr.ModuleKinds = testCase.moduleKinds
+		suite.Equal(testCase.expectedCount, len(fndr.getBslFilesPaths()), testCase.moduleKinds)
+	}
+}
+
 func (suite *FinderTestSuite) TestGetBslFilesLine() {
 	lineBslFiles := suite.BaseFinder.getBslFilesLine()
 	lineBslFilesUnicode := suite.BaseFinderUnicodeStdOut.getBslFilesLine()
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Languages of modules analyzed by BSL plugin of SonarQube
+const (
+	LanguageBSL = "bsl" // 1C:Enterprise language, files *.bsl
+	LanguageOS  = "os"  // OneScript, files *.os
+)
+
+// DefaultExtensions is a list of extensions of module files collected by default
+var DefaultExtensions = []string{".bsl"}
+
+// languagesByExt is a map of extensions of module files to languages
+var languagesByExt = map[string]string{
+	".bsl": LanguageBSL,
+	".os":  LanguageOS,
+}
+
+// languagesOrder is an order of groups of modules in output
+var languagesOrder = []string{LanguageBSL, LanguageOS}
+
+// NormalizeExt is the method for add leading dot to extension like "os"
+func NormalizeExt(ext string) string {
+	ext = strings.TrimSpace(ext)
+	if len(ext) != 0 && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return strings.ToLower(ext)
+}
+
+// moduleLanguage returns language of module by extension of its file,
+// extension without dot is used as language for unknown extensions
+func moduleLanguage(filePath string) string {
+	ext := strings.ToLower(path.Ext(filePath))
+	if language, ok := languagesByExt[ext]; ok {
+		return language
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// languageRank returns position of group of modules of language in output
+func languageRank(language string) int {
+	for idx, lang := range languagesOrder {
+		if lang == language {
+			return idx
+		}
+	}
+	return len(languagesOrder)
+}
+
+// modulePatterns returns patterns of names of module files for selected extensions
+func (f *Finder) modulePatterns() []string {
+
+	extensions := f.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+
+	var patterns []string
+	for _, ext := range extensions {
+		patterns = append(patterns, "*"+ext)
+	}
+
+	return patterns
+}
+
+// groupByLanguage orders paths to modules by groups of languages keeping order inside each group
+func (f *Finder) groupByLanguage(filesPaths []string) []string {
+
+	sort.SliceStable(filesPaths, func(i, j int) bool {
+		langI, langJ := moduleLanguage(filesPaths[i]), moduleLanguage(filesPaths[j])
+		if rankI, rankJ := languageRank(langI), languageRank(langJ); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return langI < langJ
+	})
+
+	if f.Logging && len(f.Extensions) > 1 {
+		countByLanguage := make(map[string]int)
+		var languages []string
+		for _, filePath := range filesPaths {
+			language := moduleLanguage(filePath)
+			if countByLanguage[language] == 0 {
+				languages = append(languages, language)
+			}
+			countByLanguage[language]++
+		}
+		for _, language := range languages {
+			f.Logger.Printf(">>> Количество модулей языка %s: %d", language, countByLanguage[language])
+		}
+	}
+
+	return filesPaths
+}
@@ -0,0 +1,68 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeExt(t *testing.T) {
+	assert.Equal(t, ".os", NormalizeExt("os"))
+	assert.Equal(t, ".os", NormalizeExt(".OS"))
+	assert.Equal(t, ".bsl", NormalizeExt(" .bsl "))
+	assert.Equal(t, "", NormalizeExt(""))
+}
+
+func TestModuleLanguage(t *testing.T) {
+	assert.Equal(t, LanguageBSL, moduleLanguage("Catalogs/Справочник1/Ext/ObjectModule.bsl"))
+	assert.Equal(t, LanguageOS, moduleLanguage("DataProcessors/Обработка9/Ext/Сборка.os"))
+	assert.Equal(t, "txt", moduleLanguage("Readme.txt"))
+}
+
+func TestGroupByLanguage(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	assert.Equal(t, []string{"b.bsl", "a.bsl", "b.os", "a.os", "a.txt"},
+		fndr.groupByLanguage([]string{"a.txt", "b.os", "b.bsl", "a.os", "a.bsl"}))
+}
+
+func TestExtensions(t *testing.T) {
+	testTable := []struct {
+		extensions    []string
+		expectedPaths []string
+	}{
+		{
+			nil,
+			[]string{
+				"DataProcessors/Обработка9/Ext/ManagerModule.bsl",
+				"DataProcessors/Обработка9/Ext/ObjectModule.bsl",
+			},
+		},
+		{
+			[]string{".bsl", ".os"},
+			[]string{
+				"DataProcessors/Обработка9/Ext/ManagerModule.bsl",
+				"DataProcessors/Обработка9/Ext/ObjectModule.bsl",
+				"DataProcessors/Обработка9/Ext/Сборка.os",
+			},
+		},
+		{
+			[]string{".os"},
+			[]string{
+				"DataProcessors/Обработка9/Ext/Сборка.os",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, "Обработка9")
+		fndr.Extensions = testCase.extensions
+		fndr.Scope = ScopeNames
+		assert.Equal(t, testCase.expectedPaths, fndr.getAllBslFilesPaths(), testCase.extensions)
+	}
+
+	// modules of OneScript follow modules of 1C:Enterprise language
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+	fndr.Extensions = []string{".os", ".bsl"}
+	bslFilesPaths := fndr.getAllBslFilesPaths()
+	assert.Equal(t, "DataProcessors/Обработка9/Ext/Сборка.os", bslFilesPaths[len(bslFilesPaths)-1])
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"path"
+	"strings"
+)
+
+// Layouts of configuration sources
+const (
+	LayoutDesigner = "designer" // xml dump of Designer: Subsystems/X.xml, Catalogs/X/Ext/ObjectModule.bsl
+	LayoutEDT      = "edt"      // 1C:EDT project: Subsystems/X/X.mdo, Catalogs/X/ObjectModule.bsl
+	LayoutExternal = "external" // xml dumps of external data processors and reports: X.xml, X/Ext/ObjectModule.bsl
+	LayoutFlat     = "flat"     // plain xml dump of Designer: Catalog.X.xml, Catalog.X.ObjectModule.txt
+	LayoutUnknown  = "unknown"  // folder without sources of configuration
+)
+
+// detectLayout returns layout of configuration sources in folder
+func detectLayout(files fileSystem, srcdir string) string {
+	if exists(files, path.Join(srcdir, "Configuration", "Configuration.mdo")) {
+		return LayoutEDT
+	}
+	if exists(files, path.Join(srcdir, "Configuration.xml")) {
+		if isFlatDump(files, srcdir) {
+			return LayoutFlat
+		}
+		return LayoutDesigner
+	}
+	if len(getExternalObjectsNames(files, srcdir)) != 0 {
+		return LayoutExternal
+	}
+	return LayoutUnknown
+}
+
+// isFlatDump checks that xml dump of Designer is made in plain format
+func isFlatDump(files fileSystem, srcdir string) bool {
+
+	if di, err := readDumpInfo(files, path.Join(srcdir, "ConfigDumpInfo.xml")); err == nil && len(di.Format) != 0 {
+		return di.Format == dumpFormatPlain
+	}
+
+	// files of objects are placed in root folder like "Catalog.Справочник1.xml"
+	xmlFiles, _ := files.Glob(path.Join(srcdir, "*.*.xml"))
+	for _, xmlFile := range xmlFiles {
+		typeName, _, ok := splitMetadataName(strings.TrimSuffix(path.Base(xmlFile), ".xml"))
+		if !ok {
+			continue
+		}
+		if _, ok := LookupMetadataType(typeName); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subsystemExt returns extension of subsystem files
+func (f *Finder) subsystemExt() string {
+	if f.Layout == LayoutEDT {
+		return ".mdo"
+	}
+	return ".xml"
+}
+
+// subsystemFilePath returns path to file of subsystem by names of its parents and itself,
+// for example "Subsystems/A/Subsystems/B.xml" or "Subsystems/A/Subsystems/B/B.mdo"
+func (f *Finder) subsystemFilePath(names []string) string {
+
+	filename := f.rootSubsystemsPath
+	for idx, name := range names {
+		if idx != 0 {
+			filename = path.Join(filename, "Subsystems")
+		}
+		filename = path.Join(filename, name)
+	}
+
+	if f.Layout == LayoutEDT {
+		return path.Join(filename, names[len(names)-1]+f.subsystemExt())
+	}
+	return filename + f.subsystemExt()
+}
+
+// configurationFilePath returns path to file with properties and child objects of configuration
+func (f *Finder) configurationFilePath() string {
+	if f.Layout == LayoutEDT {
+		return path.Join(f.srcdir, "Configuration", "Configuration.mdo")
+	}
+	return path.Join(f.srcdir, "Configuration.xml")
+}
+
+// configurationModulesDir returns path to folder with modules of configuration
+func (f *Finder) configurationModulesDir() string {
+	if f.Layout == LayoutEDT {
+		return path.Join(f.srcdir, "Configuration")
+	}
+	return path.Join(f.srcdir, "Ext")
+}
+
+// metadataFilePath returns path to file with properties of metadata object by path to its folder
+// like "Catalogs/X", for example "Catalogs/X.xml" or "Catalogs/X/X.mdo"
+func (f *Finder) metadataFilePath(relPath string) string {
+	if f.Layout == LayoutEDT {
+		return path.Join(f.srcdir, relPath, path.Base(relPath)+".mdo")
+	}
+	return path.Join(f.srcdir, relPath+".xml")
+}
@@ -0,0 +1,100 @@
+package finder
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var AbsPathTestEDTFolder, _ = filepath.Abs("../tests/test_edt")
+
+func TestDetectLayout(t *testing.T) {
+	assert.Equal(t, LayoutDesigner, detectLayout(osFiles, AbsPathTestSrcFolder))
+	assert.Equal(t, LayoutEDT, detectLayout(osFiles, AbsPathTestEDTFolder))
+	assert.Equal(t, LayoutEDT, NewFinder(AbsPathTestEDTFolder, "").Layout)
+}
+
+func TestSubsystemFilePath(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	filename := fndr.subsystemFilePath([]string{"рн_Супер", "рн_упс"})
+	assert.Equal(t, path.Join(AbsPathTestSrcFolder, "Subsystems/рн_Супер/Subsystems/рн_упс.xml"), filename)
+	assert.Equal(t, []string{"рн_Супер", "рн_упс"}, fndr.subsystemNames(filename))
+
+	fndr = NewFinder(AbsPathTestEDTFolder, "")
+	filename = fndr.subsystemFilePath([]string{"рн_Супер", "рн_пип"})
+	assert.Equal(t, path.Join(AbsPathTestEDTFolder, "Subsystems/рн_Супер/Subsystems/рн_пип/рн_пип.mdo"), filename)
+	assert.Equal(t, []string{"рн_Супер", "рн_пип"}, fndr.subsystemNames(filename))
+	assert.True(t, fndr.isDescendantSubsystem(filename, fndr.subsystemFilePath([]string{"рн_Супер"})))
+	assert.False(t, fndr.isDescendantSubsystem(fndr.subsystemFilePath([]string{"рн_Супер"}), filename))
+}
+
+func TestReadEDTSubsystem(t *testing.T) {
+	s, err := readSubsystem(osFiles, path.Join(AbsPathTestEDTFolder, "Subsystems/рн_Супер/рн_Супер.mdo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "рн_Супер", s.Name)
+	assert.Equal(t, []string{"Рн супер"}, s.Synonyms)
+	assert.Equal(t, []string{"Catalog.Справочник1", "ChartOfAccounts.рн_Хозрасчетный"}, s.Content)
+	assert.Equal(t, []string{"рн_пип"}, s.Children)
+}
+
+func TestReadEDTConfiguration(t *testing.T) {
+	c, err := readConfiguration(osFiles, path.Join(AbsPathTestEDTFolder, "Configuration/Configuration.mdo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Конфигурация", c.Name)
+	assert.Equal(t, 7, len(c.ChildObjects.Items))
+	assert.Equal(t, "ChartOfAccounts", c.ChildObjects.Items[6].XMLName.Local)
+	assert.Equal(t, "рн_Хозрасчетный", c.ChildObjects.Items[6].Name)
+}
+
+func TestEDTBslFilesPaths(t *testing.T) {
+	expectedPaths := []string{
+		"Catalogs/Справочник1/Forms/ФормаЭлемента/Module.bsl",
+		"Catalogs/Справочник1/ManagerModule.bsl",
+		"Catalogs/Справочник1/ObjectModule.bsl",
+		"ChartsOfAccounts/рн_Хозрасчетный/ManagerModule.bsl",
+		"CommonModules/рн_ОбщийМодуль/Module.bsl",
+		"Documents/Документ1/Commands/Команда1/CommandModule.bsl",
+		"Documents/Документ1/ObjectModule.bsl",
+	}
+
+	testTable := []struct {
+		phrases   string
+		match     string
+		scope     string
+		recursive bool
+	}{
+		{"рн_", MatchPrefix, ScopeSubsystems, false},
+		{"рн_Супер", MatchPrefix, ScopeSubsystems, true},
+		{"рн_Супер/**", MatchPrefix, ScopeSubsystems, false},
+		{"Рн*", MatchGlob, ScopeSubsystems, false},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestEDTFolder, testCase.phrases)
+		fndr.Match = testCase.match
+		fndr.Scope = testCase.scope
+		fndr.Recursive = testCase.recursive
+		bslFilesPaths := fndr.getBslFilesPaths()
+		sort.Strings(bslFilesPaths)
+		assert.Equal(t, expectedPaths, bslFilesPaths, testCase.phrases)
+	}
+
+	fndr := NewFinder(AbsPathTestEDTFolder, "рн_ !рн_Супер/рн_пип")
+	assert.Equal(t, 4, len(fndr.getBslFilesPaths()))
+
+	fndr = NewFinder(AbsPathTestEDTFolder, "рн_")
+	fndr.Scope = ScopeNames
+	assert.Equal(t, []string{"ChartOfAccounts.рн_Хозрасчетный", "CommonModule.рн_ОбщийМодуль"}, fndr.getSliceMetadataName())
+
+	fndr = NewFinder(AbsPathTestEDTFolder, "рн_")
+	fndr.ConfigModules = []string{ConfigModulesAll}
+	fndr.ModuleKinds = []ModuleKind{FormModule, CommonModule}
+	assert.Equal(t, []string{
+		"Configuration/SessionModule.bsl",
+		"Catalogs/Справочник1/Forms/ФормаЭлемента/Module.bsl",
+		"CommonModules/рн_ОбщийМодуль/Module.bsl",
+	}, fndr.getBslFilesPaths())
+}
@@ -0,0 +1,258 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleKind is a kind of bsl module that metadata object can hold
+type ModuleKind string
+
+// Kinds of bsl modules
+const (
+	ObjectModule              ModuleKind = "ObjectModule"
+	ManagerModule             ModuleKind = "ManagerModule"
+	FormModule                ModuleKind = "FormModule"
+	CommandModule             ModuleKind = "CommandModule"
+	RecordSetModule           ModuleKind = "RecordSetModule"
+	ValueManagerModule        ModuleKind = "ValueManagerModule"
+	CommonModule              ModuleKind = "Module"
+	ManagedApplicationModule  ModuleKind = "ManagedApplicationModule"
+	OrdinaryApplicationModule ModuleKind = "OrdinaryApplicationModule"
+	SessionModule             ModuleKind = "SessionModule"
+	ExternalConnectionModule  ModuleKind = "ExternalConnectionModule"
+)
+
+// ObjectModuleKinds is a list of kinds of modules that metadata objects can hold
+var ObjectModuleKinds = []ModuleKind{
+	ObjectModule,
+	ManagerModule,
+	FormModule,
+	CommandModule,
+	RecordSetModule,
+	ValueManagerModule,
+	CommonModule,
+}
+
+// ConfigurationModuleKinds is a list of modules of configuration in root "Ext" folder
+var ConfigurationModuleKinds = []ModuleKind{
+	ManagedApplicationModule,
+	OrdinaryApplicationModule,
+	SessionModule,
+	ExternalConnectionModule,
+}
+
+// MetadataType is a description of 1C metadata class
+type MetadataType struct {
+	Name        string       // english name, for example Catalog
+	NameRu      string       // russian name, for example Справочник
+	Dir         string       // folder of the configuration dump, for example Catalogs
+	ModuleKinds []ModuleKind // kinds of modules that object of the class can hold
+}
+
+// kinds of modules for most of applied metadata classes
+var appliedModuleKinds = []ModuleKind{ObjectModule, ManagerModule, FormModule, CommandModule}
+
+// MetadataTypes is a registry of all 1C metadata classes
+var MetadataTypes = []MetadataType{
+	{"Language", "Язык", "Languages", nil},
+	{"Subsystem", "Подсистема", "Subsystems", nil},
+	{"StyleItem", "ЭлементСтиля", "StyleItems", nil},
+	{"Style", "Стиль", "Styles", nil},
+	{"CommonPicture", "ОбщаяКартинка", "CommonPictures", nil},
+	{"Interface", "Интерфейс", "Interfaces", nil},
+	{"SessionParameter", "ПараметрСеанса", "SessionParameters", nil},
+	{"Role", "Роль", "Roles", nil},
+	{"CommonTemplate", "ОбщийМакет", "CommonTemplates", nil},
+	{"FilterCriterion", "КритерийОтбора", "FilterCriteria", []ModuleKind{ManagerModule, FormModule, CommandModule}},
+	{"CommonModule", "ОбщийМодуль", "CommonModules", []ModuleKind{CommonModule}},
+	{"CommonAttribute", "ОбщийРеквизит", "CommonAttributes", nil},
+	{"ExchangePlan", "ПланОбмена", "ExchangePlans", appliedModuleKinds},
+	{"XDTOPackage", "ПакетXDTO", "XDTOPackages", nil},
+	{"WebService", "WebСервис", "WebServices", []ModuleKind{CommonModule}},
+	{"HTTPService", "HTTPСервис", "HTTPServices", []ModuleKind{CommonModule}},
+	{"WSReference", "WSСсылка", "WSReferences", nil},
+	{"IntegrationService", "СервисИнтеграции", "IntegrationServices", []ModuleKind{CommonModule}},
+	{"Bot", "Бот", "Bots", []ModuleKind{CommonModule}},
+	{"EventSubscription", "ПодпискаНаСобытие", "EventSubscriptions", nil},
+	{"ScheduledJob", "РегламентноеЗадание", "ScheduledJobs", nil},
+	{"SettingsStorage", "ХранилищеНастроек", "SettingsStorages", []ModuleKind{ManagerModule, FormModule}},
+	{"FunctionalOption", "ФункциональнаяОпция", "FunctionalOptions", nil},
+	{"FunctionalOptionsParameter", "ПараметрФункциональныхОпций", "FunctionalOptionsParameters", nil},
+	{"DefinedType", "ОпределяемыйТип", "DefinedTypes", nil},
+	{"CommonCommand", "ОбщаяКоманда", "CommonCommands", []ModuleKind{CommandModule}},
+	{"CommandGroup", "ГруппаКоманд", "CommandGroups", nil},
+	{"Constant", "Константа", "Constants", []ModuleKind{ValueManagerModule, ManagerModule}},
+	{"CommonForm", "ОбщаяФорма", "CommonForms", []ModuleKind{FormModule}},
+	{"Catalog", "Справочник", "Catalogs", appliedModuleKinds},
+	{"Document", "Документ", "Documents", appliedModuleKinds},
+	{"DocumentNumerator", "НумераторДокументов", "DocumentNumerators", nil},
+	{"Sequence", "Последовательность", "Sequences", []ModuleKind{RecordSetModule}},
+	{"DocumentJournal", "ЖурналДокументов", "DocumentJournals", []ModuleKind{ManagerModule, FormModule, CommandModule}},
+	{"Enum", "Перечисление", "Enums", []ModuleKind{ManagerModule, FormModule, CommandModule}},
+	{"Report", "Отчет", "Reports", appliedModuleKinds},
+	{"DataProcessor", "Обработка", "DataProcessors", appliedModuleKinds},
+	{"InformationRegister", "РегистрСведений", "InformationRegisters", []ModuleKind{RecordSetModule, ManagerModule, FormModule, CommandModule}},
+	{"AccumulationRegister", "РегистрНакопления", "AccumulationRegisters", []ModuleKind{RecordSetModule, ManagerModule, FormModule, CommandModule}},
+	{"ChartOfCharacteristicTypes", "ПланВидовХарактеристик", "ChartsOfCharacteristicTypes", appliedModuleKinds},
+	{"ChartOfAccounts", "ПланСчетов", "ChartsOfAccounts", appliedModuleKinds},
+	{"AccountingRegister", "РегистрБухгалтерии", "AccountingRegisters", []ModuleKind{RecordSetModule, ManagerModule, FormModule, CommandModule}},
+	{"ChartOfCalculationTypes", "ПланВидовРасчета", "ChartsOfCalculationTypes", appliedModuleKinds},
+	{"CalculationRegister", "РегистрРасчета", "CalculationRegisters", []ModuleKind{RecordSetModule, ManagerModule, FormModule, CommandModule}},
+	{"BusinessProcess", "БизнесПроцесс", "BusinessProcesses", appliedModuleKinds},
+	{"Task", "Задача", "Tasks", appliedModuleKinds},
+	{"ExternalDataSource", "ВнешнийИсточникДанных", "ExternalDataSources", []ModuleKind{ObjectModule, ManagerModule, RecordSetModule, FormModule, CommandModule}},
+}
+
+// LookupMetadataType is the method for search metadata class (or class of external object) by english or russian name
+func LookupMetadataType(name string) (*MetadataType, bool) {
+	for idx := range MetadataTypes {
+		if strings.EqualFold(MetadataTypes[idx].Name, name) || strings.EqualFold(MetadataTypes[idx].NameRu, name) {
+			return &MetadataTypes[idx], true
+		}
+	}
+	for idx := range ExternalTypes {
+		if strings.EqualFold(ExternalTypes[idx].Name, name) || strings.EqualFold(ExternalTypes[idx].NameRu, name) {
+			return &ExternalTypes[idx], true
+		}
+	}
+	return nil, false
+}
+
+// HasModuleKind is the method for check that object of the class can hold module of the kind
+func (mt *MetadataType) HasModuleKind(kind ModuleKind) bool {
+	for _, mk := range mt.ModuleKinds {
+		if mk == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseModuleKind is the method for search kind of module by name like "ObjectModule" or short name like "object"
+func ParseModuleKind(name string) (ModuleKind, bool) {
+	if strings.EqualFold(name, "common") {
+		return CommonModule, true
+	}
+	for _, kind := range append(ObjectModuleKinds, ConfigurationModuleKinds...) {
+		if strings.EqualFold(name, string(kind)) || strings.EqualFold(name+"Module", string(kind)) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// classifyModule returns kind of module by path to its file like "Forms/Форма/Ext/Form/Module.bsl",
+// path can be relative or absolute
+func classifyModule(filePath string) (ModuleKind, bool) {
+
+	parts := strings.Split(filepath.ToSlash(filePath), "/")
+	name := strings.TrimSuffix(parts[len(parts)-1], path.Ext(parts[len(parts)-1]))
+
+	if name == "Module" {
+		// only folders of module are checked because sources can be placed in any folder like "/builds/Forms/src"
+		parent := func(level int) string {
+			if level >= len(parts) {
+				return ""
+			}
+			return parts[len(parts)-1-level]
+		}
+		switch {
+		case parent(1) == "Form" && parent(2) == "Ext":
+			// module of form of Designer dump is placed in "Forms/<name>/Ext/Form" folder or in folder of common form
+			return FormModule, true
+		case parent(1) == "Ext" && parent(3) == "CommonModules":
+			return CommonModule, true
+		case parent(2) == "Forms" || parent(2) == "CommonForms":
+			// module of form of EDT project is placed in "Forms/<name>" folder or in folder of common form
+			return FormModule, true
+		}
+		return CommonModule, true
+	}
+
+	for _, kind := range append(ObjectModuleKinds, ConfigurationModuleKinds...) {
+		if name == string(kind) {
+			return kind, true
+		}
+	}
+
+	return "", false
+}
+
+// filterModuleKinds leaves only files of modules of selected kinds
+func (f *Finder) filterModuleKinds(filesPaths []string) []string {
+
+	if len(f.ModuleKinds) == 0 {
+		return filesPaths
+	}
+
+	var filteredPaths []string
+
+	for _, filePath := range filesPaths {
+		kind, ok := classifyModule(filePath)
+		if !ok {
+			continue
+		}
+		for _, selectedKind := range f.ModuleKinds {
+			if kind == selectedKind {
+				filteredPaths = append(filteredPaths, filePath)
+				break
+			}
+		}
+	}
+
+	return filteredPaths
+}
+
+// splitMetadataName splits full metadata name like "Catalog.Справочник1" to class and object name
+func splitMetadataName(fullName string) (typeName string, objectName string, ok bool) {
+	idx := strings.Index(fullName, ".")
+	if idx <= 0 || idx == len(fullName)-1 {
+		return "", "", false
+	}
+	return fullName[:idx], fullName[idx+1:], true
+}
+
+// normalizeMetadataName returns full metadata name with english name of metadata class
+func normalizeMetadataName(fullName string) string {
+	typeName, objectName, ok := splitMetadataName(fullName)
+	if !ok {
+		return fullName
+	}
+	mt, ok := LookupMetadataType(typeName)
+	if !ok {
+		return fullName
+	}
+	return mt.Name + "." + objectName
+}
+
+// metadataRelPath returns path to folder of metadata object relative to root of the configuration dump
+func metadataRelPath(fullName string) (string, bool) {
+	typeName, objectName, ok := splitMetadataName(fullName)
+	if !ok {
+		return "", false
+	}
+	mt, ok := LookupMetadataType(typeName)
+	if !ok {
+		return "", false
+	}
+	return path.Join(mt.Dir, objectName), true
+}
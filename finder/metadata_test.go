@@ -0,0 +1,182 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupMetadataType(t *testing.T) {
+	testTable := []struct {
+		name   string
+		nameRu string
+		dir    string
+	}{
+		{"Language", "Язык", "Languages"},
+		{"Subsystem", "Подсистема", "Subsystems"},
+		{"StyleItem", "ЭлементСтиля", "StyleItems"},
+		{"Style", "Стиль", "Styles"},
+		{"CommonPicture", "ОбщаяКартинка", "CommonPictures"},
+		{"Interface", "Интерфейс", "Interfaces"},
+		{"SessionParameter", "ПараметрСеанса", "SessionParameters"},
+		{"Role", "Роль", "Roles"},
+		{"CommonTemplate", "ОбщийМакет", "CommonTemplates"},
+		{"FilterCriterion", "КритерийОтбора", "FilterCriteria"},
+		{"CommonModule", "ОбщийМодуль", "CommonModules"},
+		{"CommonAttribute", "ОбщийРеквизит", "CommonAttributes"},
+		{"ExchangePlan", "ПланОбмена", "ExchangePlans"},
+		{"XDTOPackage", "ПакетXDTO", "XDTOPackages"},
+		{"WebService", "WebСервис", "WebServices"},
+		{"HTTPService", "HTTPСервис", "HTTPServices"},
+		{"WSReference", "WSСсылка", "WSReferences"},
+		{"IntegrationService", "СервисИнтеграции", "IntegrationServices"},
+		{"Bot", "Бот", "Bots"},
+		{"EventSubscription", "ПодпискаНаСобытие", "EventSubscriptions"},
+		{"ScheduledJob", "РегламентноеЗадание", "ScheduledJobs"},
+		{"SettingsStorage", "ХранилищеНастроек", "SettingsStorages"},
+		{"FunctionalOption", "ФункциональнаяОпция", "FunctionalOptions"},
+		{"FunctionalOptionsParameter", "ПараметрФункциональныхОпций", "FunctionalOptionsParameters"},
+		{"DefinedType", "ОпределяемыйТип", "DefinedTypes"},
+		{"CommonCommand", "ОбщаяКоманда", "CommonCommands"},
+		{"CommandGroup", "ГруппаКоманд", "CommandGroups"},
+		{"Constant", "Константа", "Constants"},
+		{"CommonForm", "ОбщаяФорма", "CommonForms"},
+		{"Catalog", "Справочник", "Catalogs"},
+		{"Document", "Документ", "Documents"},
+		{"DocumentNumerator", "НумераторДокументов", "DocumentNumerators"},
+		{"Sequence", "Последовательность", "Sequences"},
+		{"DocumentJournal", "ЖурналДокументов", "DocumentJournals"},
+		{"Enum", "Перечисление", "Enums"},
+		{"Report", "Отчет", "Reports"},
+		{"DataProcessor", "Обработка", "DataProcessors"},
+		{"InformationRegister", "РегистрСведений", "InformationRegisters"},
+		{"AccumulationRegister", "РегистрНакопления", "AccumulationRegisters"},
+		{"ChartOfCharacteristicTypes", "ПланВидовХарактеристик", "ChartsOfCharacteristicTypes"},
+		{"ChartOfAccounts", "ПланСчетов", "ChartsOfAccounts"},
+		{"AccountingRegister", "РегистрБухгалтерии", "AccountingRegisters"},
+		{"ChartOfCalculationTypes", "ПланВидовРасчета", "ChartsOfCalculationTypes"},
+		{"CalculationRegister", "РегистрРасчета", "CalculationRegisters"},
+		{"BusinessProcess", "БизнесПроцесс", "BusinessProcesses"},
+		{"Task", "Задача", "Tasks"},
+		{"ExternalDataSource", "ВнешнийИсточникДанных", "ExternalDataSources"},
+	}
+
+	assert.Equal(t, len(MetadataTypes), len(testTable))
+
+	for _, testCase := range testTable {
+		mt, ok := LookupMetadataType(testCase.name)
+		if assert.True(t, ok, testCase.name) {
+			assert.Equal(t, testCase.dir, mt.Dir)
+			assert.Equal(t, testCase.nameRu, mt.NameRu)
+		}
+
+		mt, ok = LookupMetadataType(testCase.nameRu)
+		if assert.True(t, ok, testCase.nameRu) {
+			assert.Equal(t, testCase.name, mt.Name)
+		}
+	}
+
+	_, ok := LookupMetadataType("Unknown")
+	assert.False(t, ok)
+}
+
+func TestHasModuleKind(t *testing.T) {
+	catalog, _ := LookupMetadataType("Catalog")
+	assert.True(t, catalog.HasModuleKind(ObjectModule))
+	assert.False(t, catalog.HasModuleKind(RecordSetModule))
+
+	register, _ := LookupMetadataType("InformationRegister")
+	assert.True(t, register.HasModuleKind(RecordSetModule))
+	assert.False(t, register.HasModuleKind(ObjectModule))
+
+	role, _ := LookupMetadataType("Role")
+	assert.False(t, role.HasModuleKind(ObjectModule))
+}
+
+func TestMetadataRelPath(t *testing.T) {
+	testTable := []struct {
+		fullName     string
+		expectedPath string
+		expectedOk   bool
+	}{
+		{"Catalog.Справочник1", "Catalogs/Справочник1", true},
+		{"ChartOfAccounts.Хозрасчетный", "ChartsOfAccounts/Хозрасчетный", true},
+		{"BusinessProcess.Задание", "BusinessProcesses/Задание", true},
+		{"FilterCriterion.Связи", "FilterCriteria/Связи", true},
+		{"Справочник.Справочник1", "Catalogs/Справочник1", true},
+		{"Unknown.Объект", "", false},
+		{"Catalog", "", false},
+		{"Catalog.", "", false},
+	}
+
+	for _, testCase := range testTable {
+		relPath, ok := metadataRelPath(testCase.fullName)
+		assert.Equal(t, testCase.expectedOk, ok, testCase.fullName)
+		assert.Equal(t, testCase.expectedPath, relPath, testCase.fullName)
+	}
+}
+
+func TestNormalizeMetadataName(t *testing.T) {
+	assert.Equal(t, "Catalog.Справочник1", normalizeMetadataName("Справочник.Справочник1"))
+	assert.Equal(t, "Catalog.Справочник1", normalizeMetadataName("Catalog.Справочник1"))
+	assert.Equal(t, "Unknown.Объект", normalizeMetadataName("Unknown.Объект"))
+}
+
+func TestParseModuleKind(t *testing.T) {
+	testTable := []struct {
+		name         string
+		expectedKind ModuleKind
+		expectedOk   bool
+	}{
+		{"ObjectModule", ObjectModule, true},
+		{"object", ObjectModule, true},
+		{"Manager", ManagerModule, true},
+		{"form", FormModule, true},
+		{"command", CommandModule, true},
+		{"recordset", RecordSetModule, true},
+		{"valuemanager", ValueManagerModule, true},
+		{"common", CommonModule, true},
+		{"SessionModule", SessionModule, true},
+		{"unknown", "", false},
+	}
+
+	for _, testCase := range testTable {
+		kind, ok := ParseModuleKind(testCase.name)
+		assert.Equal(t, testCase.expectedOk, ok, testCase.name)
+		assert.Equal(t, testCase.expectedKind, kind, testCase.name)
+	}
+}
+
+func TestClassifyModule(t *testing.T) {
+	testTable := []struct {
+		filePath     string
+		expectedKind ModuleKind
+		expectedOk   bool
+	}{
+		{"Catalogs/Справочник1/Ext/ObjectModule.bsl", ObjectModule, true},
+		{"Catalogs/Справочник1/Ext/ManagerModule.bsl", ManagerModule, true},
+		{"Catalogs/Справочник1/Forms/ФормаЭлемента/Ext/Form/Module.bsl", FormModule, true},
+		{"Catalogs/Справочник1/Commands/Команда/Ext/CommandModule.bsl", CommandModule, true},
+		{"InformationRegisters/Регистр/Ext/RecordSetModule.bsl", RecordSetModule, true},
+		{"Constants/Константа/Ext/ValueManagerModule.bsl", ValueManagerModule, true},
+		{"CommonModules/ОбщийМодуль/Ext/Module.bsl", CommonModule, true},
+		{"CommonForms/ОбщаяФорма/Ext/Form/Module.bsl", FormModule, true},
+		{"CommonCommands/ОбщаяКоманда/Ext/CommandModule.bsl", CommandModule, true},
+		{"Ext/SessionModule.bsl", SessionModule, true},
+		{"Catalogs/Справочник1/Forms/ФормаЭлемента/Module.bsl", FormModule, true},
+		{"CommonForms/ОбщаяФорма/Module.bsl", FormModule, true},
+		{"CommonModules/ОбщийМодуль/Module.bsl", CommonModule, true},
+		{"CommonModules/Forms/Ext/Module.bsl", CommonModule, true},
+		{"/tmp/Forms/edt/CommonModules/ОбщийМодуль/Module.bsl", CommonModule, true},
+		{"/builds/CommonForms/src/cf/CommonModules/ОбщийМодуль/Ext/Module.bsl", CommonModule, true},
+		{"/tmp/Forms/edt/Catalogs/Справочник1/Forms/ФормаЭлемента/Module.bsl", FormModule, true},
+		{"Module.bsl", CommonModule, true},
+		{"Catalogs/Справочник1/Ext/Unknown.bsl", "", false},
+	}
+
+	for _, testCase := range testTable {
+		kind, ok := classifyModule(testCase.filePath)
+		assert.Equal(t, testCase.expectedOk, ok, testCase.filePath)
+		assert.Equal(t, testCase.expectedKind, kind, testCase.filePath)
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// otherModuleKey is a key of module for selected files which are not in content of matched subsystems
+const otherModuleKey = "other"
+
+// sonarModule is a module of multi-module SonarQube project made of top level matched subsystem
+type sonarModule struct {
+	Key     string   // key of module like "rn_Super"
+	Name    string   // name of module in SonarQube, synonym or name of subsystem
+	Sources string   // path to sources of module
+	Files   []string // output paths to module files
+}
+
+// transliteration of cyrillic letters for keys of modules
+var moduleKeyLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// moduleKey returns key of module allowed by SonarQube (latin letters, digits, "-", "_", ".") by name of subsystem
+func moduleKey(name string) string {
+
+	var key strings.Builder
+
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r)):
+			key.WriteRune(r)
+		case unicode.IsLetter(r):
+			letters, ok := moduleKeyLetters[unicode.ToLower(r)]
+			if !ok {
+				key.WriteRune('_')
+				continue
+			}
+			if unicode.IsUpper(r) && len(letters) != 0 {
+				letters = strings.ToUpper(letters[:1]) + letters[1:]
+			}
+			key.WriteString(letters)
+		default:
+			key.WriteRune('_')
+		}
+	}
+
+	if key.Len() == 0 {
+		return "module"
+	}
+
+	return key.String()
+}
+
+// getTopSubsystemsFilesPaths returns matched subsystems which parents are not matched
+// and all matched subsystems of each of them including itself
+func (f *Finder) getTopSubsystemsFilesPaths(subsystemsFilesPaths []string) ([]string, map[string][]string) {
+
+	matched := make(map[string]bool)
+	for _, sPath := range subsystemsFilesPaths {
+		matched[sPath] = true
+	}
+
+	var tops []string
+	members := make(map[string][]string)
+
+	for _, sPath := range subsystemsFilesPaths {
+
+		// the highest matched parent
+		top := sPath
+		names := f.subsystemNames(sPath)
+		for idx := 1; idx < len(names); idx++ {
+			if parent := f.subsystemFilePath(names[:idx]); matched[parent] {
+				top = parent
+				break
+			}
+		}
+
+		if _, ok := members[top]; !ok {
+			tops = append(tops, top)
+		}
+		members[top] = append(members[top], sPath)
+	}
+
+	sort.Slice(tops, func(i, j int) bool {
+		return f.subsystemHierarchicalPath(tops[i]) < f.subsystemHierarchicalPath(tops[j])
+	})
+
+	return tops, members
+}
+
+// moduleFilesPaths returns output paths to files of module compacted to globs if it is set
+func (f *Finder) moduleFilesPaths(filesPaths []string) []string {
+	if f.Compact {
+		filesPaths = f.compactPaths(filesPaths)
+	}
+	return f.prefixPaths(filesPaths)
+}
+
+// getSonarModules returns modules of source root for top level matched subsystems,
+// each selected file is placed to the first module, other selected files are placed to module "other"
+func (f *Finder) getSonarModules() []sonarModule {
+
+	var modules []sonarModule
+
+	sources := f.Prefix
+	if len(sources) == 0 || f.Abspath {
+		sources = "."
+	}
+
+	// modules contain only files of resolved scope
+	selectedPaths := f.getBslFilesPaths()
+	selected := make(map[string]bool)
+	for _, filePath := range selectedPaths {
+		selected[filePath] = true
+	}
+
+	// subsystems matched by selection of files are used, they are not used for selection by names
+	var tops []string
+	var members map[string][]string
+	if f.Scope != ScopeNames {
+		tops, members = f.getTopSubsystemsFilesPaths(f.subsystemsPaths)
+	}
+
+	assigned := make(map[string]string)
+
+	for _, top := range tops {
+
+		s, err := f.parsedSubsystem(top)
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+
+		m := sonarModule{Key: moduleKey(s.Name), Name: s.Name, Sources: sources}
+		if len(s.Synonyms) != 0 && len(s.Synonyms[0]) != 0 {
+			m.Name = s.Synonyms[0]
+		}
+
+		// objects of content of matched subsystems are collected by selection of files
+		chains := make(map[string]bool)
+		for _, sPath := range members[top] {
+			chains[f.subsystemHierarchicalPath(sPath)] = true
+		}
+		var objectsNames []string
+		for name, objectChains := range f.subsystemsByName {
+			for _, chain := range objectChains {
+				if chains[chain] {
+					objectsNames = append(objectsNames, name)
+					break
+				}
+			}
+		}
+		sort.Strings(objectsNames)
+
+		var filesPaths []string
+		for _, name := range objectsNames {
+			for _, filePath := range f.getObjectBslFilesPaths(name) {
+				if !selected[filePath] {
+					continue
+				}
+				if module, ok := assigned[filePath]; ok {
+					if module != m.Key && f.Logging {
+						f.Logger.Printf("Модуль %s уже включен в модуль проекта %s", filePath, module)
+					}
+					continue
+				}
+				assigned[filePath] = m.Key
+				filesPaths = append(filesPaths, filePath)
+			}
+		}
+
+		if len(filesPaths) == 0 {
+			continue
+		}
+		m.Files = f.moduleFilesPaths(filesPaths)
+		modules = append(modules, m)
+	}
+
+	// files of configuration modules, names scope and objects list
+	var otherPaths []string
+	for _, filePath := range selectedPaths {
+		if _, ok := assigned[filePath]; !ok {
+			otherPaths = append(otherPaths, filePath)
+		}
+	}
+	if len(otherPaths) != 0 {
+		modules = append(modules, sonarModule{Key: otherModuleKey, Name: otherModuleKey, Sources: sources, Files: f.moduleFilesPaths(otherPaths)})
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Количество модулей проекта SonarQube: %d", len(modules))
+	}
+
+	return modules
+}
+
+// getAllSonarModules returns modules of main and all additional source roots with unique keys
+func (f *Finder) getAllSonarModules() []sonarModule {
+
+	modules := f.getSonarModules()
+
+	for _, r := range f.Roots {
+		if f.Logging {
+			f.Logger.Printf(">>> Корень исходных файлов: %s", r.Srcdir)
+		}
+		rf := f.rootFinder(r)
+		rf.checkPlatformVersion()
+		modules = append(modules, rf.getSonarModules()...)
+	}
+
+	keys := make(map[string]bool)
+	for idx := range modules {
+		key := modules[idx].Key
+		for number := 2; keys[key]; number++ {
+			key = modules[idx].Key + "_" + strconv.Itoa(number)
+		}
+		keys[key] = true
+		modules[idx].Key = key
+	}
+
+	return modules
+}
+
+// setModules writes keys of modules of multi-module project to content of properties file
+func (f *Finder) setModules(content string) string {
+
+	modules := f.getAllSonarModules()
+
+	var keys []string
+	for _, m := range modules {
+		keys = append(keys, m.Key)
+	}
+	content = setPropertiesKey(content, "sonar.modules", strings.Join(keys, ","))
+
+	for _, m := range modules {
+		name := m.Name
+		if f.Unicode {
+			name = f.stringToUnicode(name)
+		}
+		content = setPropertiesKey(content, m.Key+".sonar.projectName", name)
+		content = setPropertiesKey(content, m.Key+".sonar.projectBaseDir", ".")
+		content = setPropertiesKey(content, m.Key+".sonar.sources", m.Sources)
+		content = setPropertiesKey(content, m.Key+".sonar.inclusions", f.pathsLine(m.Files))
+	}
+
+	return content
+}
@@ -0,0 +1,131 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleKey(t *testing.T) {
+	testTable := []struct {
+		name        string
+		expectedKey string
+	}{
+		{"рн_Супер", "rn_Super"},
+		{"Щит Ёж", "Schit_Ezh"},
+		{"Sales.2", "Sales.2"},
+		{"", "module"},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedKey, moduleKey(testCase.name))
+	}
+}
+
+func TestGetTopSubsystemsFilesPaths(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер/**")
+	subsystemsFilesPaths := fndr.getSubsystemsFilesPaths()
+	tops, members := fndr.getTopSubsystemsFilesPaths(subsystemsFilesPaths)
+
+	if assert.Equal(t, 1, len(tops)) {
+		assert.Equal(t, "рн_Супер", fndr.subsystemHierarchicalPath(tops[0]))
+		assert.Equal(t, len(subsystemsFilesPaths), len(members[tops[0]]))
+	}
+
+	// subsystems matched by selection of files are kept for modules
+	fndr.getBslFilesPaths()
+	assert.Equal(t, subsystemsFilesPaths, fndr.subsystemsPaths)
+}
+
+func TestGetSonarModules(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, phrases)
+	fndr.ConfigModules = []string{string(SessionModule)}
+	modules := fndr.getAllSonarModules()
+
+	// each selected file is placed to one module
+	count := make(map[string]int)
+	keys := make(map[string]bool)
+	for _, m := range modules {
+		assert.False(t, keys[m.Key], m.Key)
+		keys[m.Key] = true
+		assert.Equal(t, ".", m.Sources)
+		for _, filePath := range m.Files {
+			count[filePath]++
+		}
+	}
+	filesPaths := fndr.getBslFilesPaths()
+	assert.Equal(t, len(filesPaths), len(count))
+	for _, filePath := range filesPaths {
+		assert.Equal(t, 1, count[filePath], filePath)
+	}
+
+	last := modules[len(modules)-1]
+	assert.Equal(t, otherModuleKey, last.Key)
+	assert.Equal(t, []string{"Ext/SessionModule.bsl"}, last.Files)
+
+	// modules of additional source roots have unique keys
+	fndr = NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+	fndr.Prefix = "src/cf"
+	fndr.Roots = []Root{{Srcdir: AbsPathTestSrcFolder, Phrases: "рн_Супер", Prefix: "src/copy"}}
+	modules = fndr.getAllSonarModules()
+	if assert.Equal(t, 2, len(modules)) {
+		assert.Equal(t, "rn_Super", modules[0].Key)
+		assert.Equal(t, "src/cf", modules[0].Sources)
+		assert.Equal(t, "rn_Super_2", modules[1].Key)
+		assert.Equal(t, "src/copy", modules[1].Sources)
+		assert.Contains(t, modules[1].Files[0], "src/copy/")
+	}
+
+	// modules contain only files of resolved scope
+	fndr = NewFinder(AbsPathTestSrcFolder, "рн_")
+	fndr.ModuleKinds = []ModuleKind{ManagerModule}
+	for _, m := range fndr.getAllSonarModules() {
+		for _, filePath := range m.Files {
+			assert.True(t, strings.HasSuffix(filePath, "/ManagerModule.bsl"), filePath)
+		}
+	}
+
+	// subsystems are not used for selection by names
+	fndr = NewFinder(AbsPathTestSrcFolder, "рн_")
+	fndr.Scope = ScopeNames
+	assert.Equal(t, 0, len(fndr.getAllSonarModules()))
+}
+
+func TestWriteModulesToFile(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	sfile := path.Join(tempDir, "sonar-project.properties")
+	assert.NoError(t, ioutil.WriteFile(sfile, []byte("sonar.projectKey=erp\nsonar.modules=old\n"), 0644))
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+	fndr.Sfile = sfile
+	fndr.Modules = true
+	fndr.Compact = true
+	fndr.writeBslLineToFile()
+
+	data, err := ioutil.ReadFile(sfile)
+	assert.NoError(t, err)
+	content := string(data)
+
+	assert.Contains(t, content, "sonar.projectKey=erp\nsonar.modules=rn_Super\n")
+	assert.Contains(t, content, "rn_Super.sonar.projectName=")
+	assert.Contains(t, content, "rn_Super.sonar.projectBaseDir=.\n")
+	assert.Contains(t, content, "rn_Super.sonar.sources=.\n")
+	assert.Contains(t, content, "rn_Super.sonar.inclusions=DataProcessors/Обработка10/**/*.bsl, \\\n")
+	assert.NotContains(t, content, "old")
+
+	// keys are replaced on the next run
+	fndr.writeBslLineToFile()
+	newData, err := ioutil.ReadFile(sfile)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(newData))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readObjectsNames reads full metadata names (one per line) like "Catalog.Справочник1",
+// empty lines and lines started with "#" are skipped
+func readObjectsNames(reader io.Reader) ([]string, error) {
+
+	var objectsNames []string
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF"))
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		objectsNames = append(objectsNames, line)
+	}
+
+	return objectsNames, scanner.Err()
+}
+
+// getObjectsNamesFromList returns metadata names from objects list file or from stdin if file is "-",
+// list is read once because stdin can't be read again
+func (f *Finder) getObjectsNamesFromList() []string {
+
+	if len(f.ObjectsFile) == 0 {
+		return []string{}
+	}
+
+	if f.objectsNames == nil {
+		f.objectsNames = f.readObjectsList()
+	}
+
+	return f.objectsNames
+}
+
+// readObjectsList reads metadata names from objects list file or from stdin
+func (f *Finder) readObjectsList() []string {
+
+	var reader io.Reader = os.Stdin
+
+	if f.ObjectsFile != "-" {
+		listFile, err := os.Open(f.ObjectsFile)
+		if err != nil {
+			println(err.Error())
+			return []string{}
+		}
+		defer func(listFile *os.File) {
+			err := listFile.Close()
+			if err != nil {
+				println(err.Error())
+			}
+		}(listFile)
+		reader = listFile
+	}
+
+	objectsNames, err := readObjectsNames(reader)
+	if err != nil {
+		println(err.Error())
+		return []string{}
+	}
+	if objectsNames == nil {
+		objectsNames = []string{}
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Найдено объектов в списке: %d", len(objectsNames))
+	}
+
+	return objectsNames
+}
@@ -0,0 +1,57 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var AbsPathFixtureObjectsListFile, _ = filepath.Abs("../tests/fixture-objects-list")
+
+func TestReadObjectsNames(t *testing.T) {
+	objectsNames, err := readObjectsNames(strings.NewReader("\uFEFFCatalog.Справочник1\r\n# comment\n\n  Document.Документ3  \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Catalog.Справочник1", "Document.Документ3"}, objectsNames)
+}
+
+func TestGetObjectsNamesFromList(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	assert.Equal(t, 0, len(fndr.getObjectsNamesFromList()))
+
+	fndr.ObjectsFile = AbsPathFixtureObjectsListFile
+	assert.Equal(t, 3, len(fndr.getObjectsNamesFromList()))
+
+	// objects list only
+	assert.Equal(t, 3, len(fndr.getSliceMetadataName()))
+
+	// objects list combined with subsystems
+	fndr = NewFinder(AbsPathTestSrcFolder, phrases)
+	fndr.ObjectsFile = AbsPathFixtureObjectsListFile
+	assert.Equal(t, CountGetListMetadataName+2, len(fndr.getSliceMetadataName()))
+}
+
+func TestGetObjectsNamesFromStdin(t *testing.T) {
+
+	listFile, err := os.Open(AbsPathFixtureObjectsListFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listFile.Close()
+
+	stdin := os.Stdin
+	os.Stdin = listFile
+	defer func() { os.Stdin = stdin }()
+
+	// stdin is read once and the list is reused by exclusions after selection
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	fndr.ObjectsFile = "-"
+	assert.Equal(t, 3, len(fndr.getObjectsNamesFromList()))
+	assert.Equal(t, 3, len(fndr.getObjectsNamesFromList()))
+
+	excludedPaths := fndr.getExcludedFilesPaths()
+	assert.NotContains(t, excludedPaths, "Catalogs/**/*.bsl")
+	assert.Contains(t, excludedPaths, "Catalogs/Справочник2/**/*.bsl")
+}
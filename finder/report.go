@@ -0,0 +1,246 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+// Formats of output data
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// IsMachineReadable checks that output data of format is parsed by scripts,
+// log is printed to stderr for such formats to keep output valid
+func IsMachineReadable(format string) bool {
+	switch format {
+	case FormatJSON, FormatArgs, FormatCmd, FormatDotenv:
+		return true
+	}
+	return false
+}
+
+// Membership is a list of subsystems which contain metadata object
+type Membership struct {
+	Object     string   `json:"object"`
+	Subsystems []string `json:"subsystems"`
+	Prefixes   []string `json:"prefixes"`
+	Shared     bool     `json:"shared"`
+}
+
+// getObjectsNamesWithModules returns full names of all metadata objects that have bsl modules on disk
+func (f *Finder) getObjectsNamesWithModules() []string {
+
+	var objectsNames []string
+
+	for _, mt := range MetadataTypes {
+
+		if len(mt.ModuleKinds) == 0 {
+			continue
+		}
+
+		// folder of metadata class may be absent
+		items, err := f.files.ReadDir(path.Join(f.srcdir, mt.Dir))
+		if err != nil {
+			continue
+		}
+
+		for _, item := range items {
+			if !item.IsDir() {
+				continue
+			}
+			if len(f.getSliceFiles(path.Join(f.srcdir, mt.Dir, item.Name()), f.modulePatterns()...)) == 0 {
+				continue
+			}
+			objectsNames = append(objectsNames, mt.Name+"."+item.Name())
+		}
+	}
+
+	return objectsNames
+}
+
+// getOrphanObjectsNames returns full names of metadata objects with bsl modules which are not in any subsystem
+func (f *Finder) getOrphanObjectsNames() []string {
+
+	// all objects from content of all subsystems
+	coveredNames := make(map[string]bool)
+	for _, sPath := range f.getAllSubsystemsFilesPaths() {
+		for _, name := range f.getObjectsNamesFromSubsystem(sPath) {
+			coveredNames[normalizeMetadataName(name)] = true
+		}
+	}
+
+	var orphansNames []string
+
+	for _, name := range f.getObjectsNamesWithModules() {
+		if !coveredNames[name] {
+			orphansNames = append(orphansNames, name)
+		}
+	}
+
+	sort.Strings(orphansNames)
+
+	if f.Logging {
+		f.Logger.Printf(">>> Найдено объектов вне подсистем: %d", len(orphansNames))
+	}
+
+	return orphansNames
+}
+
+// OrphansToSTDOUT is a method for output metadata objects with bsl modules which are not in any subsystem
+func (f *Finder) OrphansToSTDOUT() {
+
+	f.checkPlatformVersion()
+
+	for _, name := range f.getOrphanObjectsNames() {
+		if f.Unicode {
+			fmt.Println(f.stringToUnicode(name))
+		} else {
+			fmt.Println(name)
+		}
+	}
+}
+
+// subsystemHierarchicalPath returns path of subsystem in hierarchy like "Parent/Child"
+func (f *Finder) subsystemHierarchicalPath(filename string) string {
+	return strings.Join(f.subsystemNames(filename), "/")
+}
+
+// getMembership returns all subsystems for each metadata object of scope (or of all subsystems without phrases)
+func (f *Finder) getMembership() []Membership {
+
+	inclPhrases, _ := splitPhrases(f.parsePhrases())
+	_, prfxs := splitSelectors(inclPhrases)
+
+	// objects of resolved scope
+	var scopeNames map[string]bool
+	if len(inclPhrases) != 0 {
+		scopeNames = make(map[string]bool)
+		for _, name := range f.getSliceMetadataName() {
+			scopeNames[normalizeMetadataName(name)] = true
+		}
+	}
+
+	membershipByName := make(map[string]*Membership)
+
+	for _, sPath := range f.getAllSubsystemsFilesPaths() {
+
+		chain := f.subsystemHierarchicalPath(sPath)
+
+		// first phrase that matches one of subsystems in chain
+		var prefix string
+		for _, name := range strings.Split(chain, "/") {
+			for _, prfx := range prfxs {
+				if matchPhrase(f.Match, prfx, name) {
+					prefix = prfx
+					break
+				}
+			}
+			if len(prefix) != 0 {
+				break
+			}
+		}
+
+		for _, name := range f.getObjectsNamesFromSubsystem(sPath) {
+
+			name = normalizeMetadataName(name)
+			if scopeNames != nil && !scopeNames[name] {
+				continue
+			}
+
+			m, ok := membershipByName[name]
+			if !ok {
+				m = &Membership{Object: name, Subsystems: []string{}, Prefixes: []string{}}
+				membershipByName[name] = m
+			}
+			m.Subsystems = append(m.Subsystems, chain)
+			if len(prefix) != 0 {
+				m.Prefixes = append(m.Prefixes, prefix)
+			}
+		}
+	}
+
+	var membership []Membership
+
+	for _, m := range membershipByName {
+		sort.Strings(m.Subsystems)
+		m.Prefixes = funk.UniqString(m.Prefixes)
+		sort.Strings(m.Prefixes)
+		m.Shared = len(m.Prefixes) > 1
+		membership = append(membership, *m)
+	}
+
+	sort.Slice(membership, func(i, j int) bool {
+		return membership[i].Object < membership[j].Object
+	})
+
+	if f.Logging {
+		f.Logger.Printf(">>> Найдено объектов в подсистемах: %d", len(membership))
+	}
+
+	return membership
+}
+
+// MembershipToSTDOUT is a method for output subsystems of each metadata object in text or json format
+func (f *Finder) MembershipToSTDOUT() {
+
+	f.checkPlatformVersion()
+
+	membership := f.getMembership()
+
+	if f.Format == FormatJSON {
+		if membership == nil {
+			membership = []Membership{}
+		}
+		out, err := json.MarshalIndent(membership, "", "  ")
+		if err != nil {
+			println(err.Error())
+			return
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	for _, m := range membership {
+
+		line := m.Object
+		if m.Shared {
+			line = line + " (shared: " + strings.Join(m.Prefixes, ", ") + ")"
+		}
+		if f.Unicode {
+			line = f.stringToUnicode(line)
+		}
+		fmt.Println(line)
+
+		// subsystems chains with indent
+		for _, chain := range m.Subsystems {
+			if f.Unicode {
+				chain = f.stringToUnicode(chain)
+			}
+			fmt.Println("\t" + chain)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package finder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var CountObjectsNamesWithModules = 36
+
+func TestGetObjectsNamesWithModules(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	objectsNames := fndr.getObjectsNamesWithModules()
+	assert.Equal(t, CountObjectsNamesWithModules, len(objectsNames))
+	assert.Contains(t, objectsNames, "Report.Отчет1")
+}
+
+func TestGetOrphanObjectsNames(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	orphansNames := fndr.getOrphanObjectsNames()
+	assert.Equal(t, []string{
+		"DataProcessor.Обработка1",
+		"DataProcessor.Обработка2",
+		"DataProcessor.Обработка3",
+		"DataProcessor.Обработка7",
+		"DataProcessor.Обработка8",
+		"Document.Документ7",
+		"Report.Отчет2",
+		"Report.Отчет5",
+	}, orphansNames)
+}
+
+func TestOrphansToSTDOUT(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	fndr.OrphansToSTDOUT()
+
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+
+	assert.Equal(t, 8, len(strings.Split(strings.TrimSpace(string(out)), "\n")))
+}
+
+func TestSubsystemHierarchicalPath(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	assert.Equal(t, "рн_Супер", fndr.subsystemHierarchicalPath(subsystemFilePath))
+	assert.Equal(t, "рн_Супер/рн_упс/общие_механизмы", fndr.subsystemHierarchicalPath(nestedSubsystemFilePath))
+}
+
+func TestGetMembership(t *testing.T) {
+	fndr := NewFinder(AbsPathTestSrcFolder, "")
+	membership := fndr.getMembership()
+	assert.Equal(t, 28, len(membership))
+
+	fndr = NewFinder(AbsPathTestSrcFolder, phrases)
+	membership = fndr.getMembership()
+	assert.Equal(t, CountGetListMetadataName, len(membership))
+
+	var shared []string
+	for _, m := range membership {
+		if m.Shared {
+			shared = append(shared, m.Object)
+		}
+		if m.Object == "Catalog.Справочник8" {
+			assert.Equal(t, []string{"пс_Доп/пс_поддоп", "рн_дубль/рн_поддубль"}, m.Subsystems)
+			assert.Equal(t, []string{"пс_", "рн_"}, m.Prefixes)
+		}
+	}
+	assert.Equal(t, []string{"Catalog.Справочник8", "DataProcessor.Обработка10", "DataProcessor.Обработка9"}, shared)
+}
+
+func TestMembershipToSTDOUT(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	fndr := NewFinder(AbsPathTestSrcFolder, phrases)
+	fndr.Format = FormatJSON
+	fndr.MembershipToSTDOUT()
+
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+
+	var membership []Membership
+	assert.NoError(t, json.Unmarshal(out, &membership))
+	assert.Equal(t, CountGetListMetadataName, len(membership))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+// rootSeparator is a separator of parts of source root description
+const rootSeparator = ";"
+
+// Root is a description of additional source root with its own parse phrases and prefix of output paths
+type Root struct {
+	Srcdir  string // path to folder with sources, for example src/cfe/Ext1
+	Phrases string // parse phrases for sources of the root
+	Prefix  string // prefix of relative output paths, path to the root by default
+}
+
+// ParseRoot is the method for parse source root description like "srcdir;parsephrases;prefix"
+func ParseRoot(value string) (Root, error) {
+
+	parts := strings.SplitN(value, rootSeparator, 3)
+
+	r := Root{Srcdir: strings.TrimSpace(parts[0])}
+	if len(r.Srcdir) == 0 {
+		return r, errors.New("path to source root is empty")
+	}
+	if len(parts) > 1 {
+		r.Phrases = parts[1]
+	}
+
+	r.Prefix = filepath.ToSlash(r.Srcdir)
+	if len(parts) > 2 {
+		r.Prefix = filepath.ToSlash(strings.TrimSpace(parts[2]))
+	}
+
+	return r, nil
+}
+
+// SourceFinder returns finder of sources by path, finders of all sources of run share each other,
+// so folder or archive of sources is opened and read once
+func (f *Finder) SourceFinder(srcdir string) *Finder {
+
+	if sf, ok := f.sources[srcdir]; ok {
+		return sf
+	}
+
+	sf := NewFinder(srcdir, "")
+	sf.sources = f.sources
+	f.sources[srcdir] = sf
+
+	return sf
+}
+
+// rootFinder returns finder of additional source root with the same options
+func (f *Finder) rootFinder(r Root) *Finder {
+
+	rf := *f
+	rf.srcdir = r.Srcdir
+	rf.phrases = r.Phrases
+	rf.rootSubsystemsPath = path.Join(r.Srcdir, "Subsystems")
+	rf.metadataNamesByID = nil
+	rf.Base = ""
+
+	// sources of root can be placed in folder or in archive which is opened once
+	sf := f.SourceFinder(r.Srcdir)
+	rf.files, rf.filesErr = sf.files, sf.filesErr
+	if rf.filesErr != nil {
+		println(rf.filesErr.Error())
+	}
+	rf.Layout = sf.Layout
+	rf.Prefix = r.Prefix
+	rf.Roots = nil
+
+	// objects list is related to main source root only
+	rf.ObjectsFile = ""
+	rf.objectsNames = nil
+	rf.subsystemsByName = nil
+	rf.subsystemsPaths = nil
+	rf.subsystemsByPath = nil
+
+	return &rf
+}
+
+// prefixPaths adds prefix of source root to relative paths
+func (f *Finder) prefixPaths(filesPaths []string) []string {
+
+	if f.Abspath || len(f.Prefix) == 0 {
+		return filesPaths
+	}
+
+	for idx, filePath := range filesPaths {
+		filesPaths[idx] = path.Join(f.Prefix, filepath.ToSlash(filePath))
+	}
+
+	return filesPaths
+}
+
+// getAllRootsPaths returns merged paths of main and all additional source roots got by function for each root
+func (f *Finder) getAllRootsPaths(getPaths func(*Finder) []string) []string {
+
+	filesPaths := f.prefixPaths(getPaths(f))
+
+	if len(f.Roots) == 0 {
+		return f.groupByLanguage(filesPaths)
+	}
+
+	for _, r := range f.Roots {
+		if f.Logging {
+			f.Logger.Printf(">>> Корень исходных файлов: %s", r.Srcdir)
+		}
+		rf := f.rootFinder(r)
+		rf.checkPlatformVersion()
+		filesPaths = append(filesPaths, rf.prefixPaths(getPaths(rf))...)
+	}
+
+	return f.groupByLanguage(funk.UniqString(filesPaths))
+}
+
+// getAllBslFilesPaths returns merged paths to bsl files of main and all additional source roots
+func (f *Finder) getAllBslFilesPaths() []string {
+
+	filesPaths := f.getAllRootsPaths((*Finder).getCompactedBslFilesPaths)
+
+	if f.Logging && len(f.Roots) != 0 {
+		f.Logger.Printf(">>> Общее количество bsl модулей для проверки: %d", len(filesPaths))
+	}
+
+	return filesPaths
+}
@@ -0,0 +1,83 @@
+package finder
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoot(t *testing.T) {
+	testTable := []struct {
+		value        string
+		expectedRoot Root
+		expectedErr  bool
+	}{
+		{"src/cfe/Ext1", Root{"src/cfe/Ext1", "", "src/cfe/Ext1"}, false},
+		{"src/cfe/Ext1;Расш1_", Root{"src/cfe/Ext1", "Расш1_", "src/cfe/Ext1"}, false},
+		{"src/cfe/Ext1;Расш1_ !Расш1_Старое;ext/Ext1", Root{"src/cfe/Ext1", "Расш1_ !Расш1_Старое", "ext/Ext1"}, false},
+		{"src/cfe/Ext1;;", Root{"src/cfe/Ext1", "", ""}, false},
+		{";Расш1_", Root{}, true},
+	}
+
+	for _, testCase := range testTable {
+		r, err := ParseRoot(testCase.value)
+		if testCase.expectedErr {
+			assert.Error(t, err, testCase.value)
+			continue
+		}
+		assert.NoError(t, err, testCase.value)
+		assert.Equal(t, testCase.expectedRoot, r, testCase.value)
+	}
+}
+
+func TestGetAllBslFilesPaths(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+	fndr.Prefix = "src/cf"
+	fndr.Extension = true
+	fndr.ExcludeAdopted = true
+	fndr.Roots = []Root{
+		{AbsPathTestExtensionFolder, "", "src/cfe/Ext1"},
+		{AbsPathTestEDTFolder, "рн_Супер/рн_пип", "src/edt"},
+	}
+
+	assert.Equal(t, []string{
+		"src/cf/DataProcessors/Обработка10/Ext/ManagerModule.bsl",
+		"src/cf/DataProcessors/Обработка10/Ext/ObjectModule.bsl",
+		"src/cf/DataProcessors/Обработка9/Ext/ManagerModule.bsl",
+		"src/cf/DataProcessors/Обработка9/Ext/ObjectModule.bsl",
+		"src/cfe/Ext1/Catalogs/Расш1_Справочник/Ext/ManagerModule.bsl",
+		"src/cfe/Ext1/Catalogs/Расш1_Справочник/Ext/ObjectModule.bsl",
+		"src/cfe/Ext1/Catalogs/Справочник1/Ext/ObjectModule.bsl",
+		"src/edt/CommonModules/рн_ОбщийМодуль/Module.bsl",
+		"src/edt/Documents/Документ1/ObjectModule.bsl",
+		"src/edt/Documents/Документ1/Commands/Команда1/CommandModule.bsl",
+	}, fndr.getAllBslFilesPaths())
+
+	// options of main finder are applied to roots, paths of roots are not changed
+	fndr.Abspath = true
+	fndr.ModuleKinds = []ModuleKind{CommandModule}
+	assert.Equal(t, []string{
+		path.Join(AbsPathTestEDTFolder, "Documents/Документ1/Commands/Команда1/CommandModule.bsl"),
+	}, fndr.getAllBslFilesPaths())
+	assert.Equal(t, AbsPathTestSrcFolder, fndr.srcdir)
+}
+
+func TestSourceFinder(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+	assert.Same(t, fndr, fndr.SourceFinder(AbsPathTestSrcFolder))
+
+	// finder of root is made once and shared by all finders of run
+	sf := fndr.SourceFinder(AbsPathTestExtensionFolder)
+	assert.Same(t, sf, fndr.SourceFinder(AbsPathTestExtensionFolder))
+	assert.Same(t, sf, sf.SourceFinder(AbsPathTestSrcFolder).SourceFinder(AbsPathTestExtensionFolder))
+	assert.Same(t, fndr, sf.SourceFinder(AbsPathTestSrcFolder))
+	assert.Equal(t, "", sf.phrases)
+
+	rf := fndr.rootFinder(Root{AbsPathTestExtensionFolder, "Расш1_", "src/cfe/Ext1"})
+	assert.Equal(t, sf.files, rf.files)
+	assert.Equal(t, sf.Layout, rf.Layout)
+	assert.Equal(t, "Расш1_", rf.phrases)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Formats of arguments of sonar-scanner
+const (
+	FormatArgs   = "args"   // arguments like -Dsonar.inclusions=... quoted for POSIX shell
+	FormatCmd    = "cmd"    // arguments quoted for cmd.exe
+	FormatDotenv = "dotenv" // SONAR_SCANNER_OPTS variable of dotenv file
+)
+
+// DefaultMaxArgsLength is a length of arguments line after which properties are written to response file,
+// it is less than limit of command line of cmd.exe
+const DefaultMaxArgsLength = 8000
+
+// DefaultResponseFile is a default path to response file with properties for long arguments line
+const DefaultResponseFile = "sonar-scanner-args.properties"
+
+// scannerOptsVariable is an environment variable with options of sonar-scanner
+const scannerOptsVariable = "SONAR_SCANNER_OPTS"
+
+// scannerProperty is a property of sonar-scanner with list of paths
+type scannerProperty struct {
+	Key   string
+	Paths []string
+}
+
+// getScannerProperties returns properties with lists of paths of main and all additional source roots
+func (f *Finder) getScannerProperties() []scannerProperty {
+
+	properties := []scannerProperty{{Key: "sonar.inclusions", Paths: f.getAllBslFilesPaths()}}
+	if f.Exclusions {
+		properties = append(properties, scannerProperty{Key: exclusionsKey, Paths: f.getAllExcludedFilesPaths()})
+	}
+
+	return properties
+}
+
+// quotePOSIX quotes argument for POSIX shell by single quotes
+func quotePOSIX(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// quoteCmd quotes argument for cmd.exe and parsing of command line by programs of Windows:
+// backslashes before quote are doubled, quote is escaped by backslash,
+// arguments with "%" and "!" are not quoted safely and are written to response file
+func quoteCmd(arg string) string {
+
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+
+	backslashes := 0
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			quoted.WriteString(strings.Repeat(`\`, backslashes*2+1))
+		default:
+			quoted.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		quoted.WriteRune(r)
+	}
+
+	// backslashes before closing quote
+	quoted.WriteString(strings.Repeat(`\`, backslashes*2))
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// quoteDotenv quotes value of dotenv file by double quotes
+func quoteDotenv(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// scannerArgs returns arguments like -Dkey=value for properties
+func scannerArgs(properties []scannerProperty) []string {
+	var args []string
+	for _, p := range properties {
+		args = append(args, "-D"+p.Key+"="+strings.Join(p.Paths, ","))
+	}
+	return args
+}
+
+// formatScannerArgs returns line of quoted arguments or dotenv line in format of output
+func (f *Finder) formatScannerArgs(args []string) string {
+
+	if f.Format == FormatDotenv {
+		return scannerOptsVariable + "=" + quoteDotenv(strings.Join(args, " "))
+	}
+
+	quote := quotePOSIX
+	if f.Format == FormatCmd {
+		quote = quoteCmd
+	}
+
+	var quotedArgs []string
+	for _, arg := range args {
+		quotedArgs = append(quotedArgs, quote(arg))
+	}
+
+	return strings.Join(quotedArgs, " ")
+}
+
+// needsResponseFile checks that arguments line is too long or arguments can't be passed safely
+func (f *Finder) needsResponseFile(args []string, line string) bool {
+
+	if f.MaxArgsLength > 0 && len(line) > f.MaxArgsLength {
+		return true
+	}
+
+	return f.hasUnsafeArgs(args)
+}
+
+// hasUnsafeArgs checks that arguments contain spaces which split options in SONAR_SCANNER_OPTS
+// or symbols "%" and "!" which are expanded by cmd.exe even in double quotes
+func (f *Finder) hasUnsafeArgs(args []string) bool {
+
+	var unsafeSymbols string
+	switch f.Format {
+	case FormatDotenv:
+		unsafeSymbols = " \t"
+	case FormatCmd:
+		unsafeSymbols = "%!"
+	default:
+		return false
+	}
+
+	for _, arg := range args {
+		if strings.ContainsAny(arg, unsafeSymbols) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeResponseFile writes properties to file for parameter project.settings of sonar-scanner,
+// not ASCII symbols are converted to unicode for any encoding of the file
+func (f *Finder) writeResponseFile(properties []scannerProperty) error {
+
+	var content strings.Builder
+	for _, p := range properties {
+		var paths []string
+		for _, filePath := range p.Paths {
+			paths = append(paths, f.stringToUnicode(filePath))
+		}
+		content.WriteString(p.Key + "=" + strings.Join(paths, ", \\\n") + "\n")
+	}
+
+	return ioutil.WriteFile(f.ResponseFile, []byte(content.String()), 0644)
+}
+
+// writeScannerArgsToSTDOUT prints arguments of sonar-scanner or dotenv line,
+// long lists of paths are written to response file
+func (f *Finder) writeScannerArgsToSTDOUT() {
+
+	properties := f.getScannerProperties()
+	args := scannerArgs(properties)
+	line := f.formatScannerArgs(args)
+
+	if f.needsResponseFile(args, line) {
+
+		responseFile := f.ResponseFile
+		if len(responseFile) == 0 {
+			responseFile = DefaultResponseFile
+			f.ResponseFile = responseFile
+		}
+		if err := f.writeResponseFile(properties); err != nil {
+			println(err.Error())
+			return
+		}
+
+		if f.Logging {
+			f.Logger.Printf(">>> Свойства записаны в файл: %s (длина аргументов %d, ограничение %d)",
+				responseFile, len(line), f.MaxArgsLength)
+		}
+
+		args = []string{"-Dproject.settings=" + responseFile}
+		line = f.formatScannerArgs(args)
+		if f.hasUnsafeArgs(args) {
+			fmt.Fprintf(os.Stderr, "WARN\tПуть к файлу %s нельзя безопасно передать в формате %s: используйте путь без пробелов и символов %% и !\n", responseFile, f.Format)
+		}
+	}
+
+	fmt.Println(line)
+}
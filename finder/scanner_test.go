@@ -0,0 +1,170 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotePOSIX(t *testing.T) {
+	testTable := []struct {
+		arg            string
+		expectedQuoted string
+	}{
+		{"-Dsonar.inclusions=a,b", "'-Dsonar.inclusions=a,b'"},
+		{"Обработка 10/$HOME", "'Обработка 10/$HOME'"},
+		{"It's", `'It'\''s'`},
+		{"", "''"},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedQuoted, quotePOSIX(testCase.arg), testCase.arg)
+	}
+}
+
+func TestQuoteCmd(t *testing.T) {
+	testTable := []struct {
+		arg            string
+		expectedQuoted string
+	}{
+		{"-Dsonar.inclusions=a,b", `"-Dsonar.inclusions=a,b"`},
+		{`Catalogs\Справочник1\Ext\ObjectModule.bsl`, `"Catalogs\Справочник1\Ext\ObjectModule.bsl"`},
+		{`a "b" & c`, `"a \"b\" & c"`},
+		{`a\"b`, `"a\\\"b"`},
+		{`src\cf\`, `"src\cf\\"`},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedQuoted, quoteCmd(testCase.arg), testCase.arg)
+	}
+}
+
+func TestQuoteDotenv(t *testing.T) {
+	assert.Equal(t, `"-Dsonar.inclusions=a,b"`, quoteDotenv("-Dsonar.inclusions=a,b"))
+	assert.Equal(t, `"\$HOME \"a\" \\ \`+"`"+`"`, quoteDotenv(`$HOME "a" \ `+"`"))
+}
+
+func TestFormatScannerArgs(t *testing.T) {
+
+	args := []string{"-Dsonar.inclusions=Catalogs/Справочник1/**/*.bsl,Ext/SessionModule.bsl", "-Dsonar.exclusions=it's"}
+
+	testTable := []struct {
+		format       string
+		expectedLine string
+	}{
+		{FormatArgs, `'-Dsonar.inclusions=Catalogs/Справочник1/**/*.bsl,Ext/SessionModule.bsl' '-Dsonar.exclusions=it'\''s'`},
+		{FormatCmd, `"-Dsonar.inclusions=Catalogs/Справочник1/**/*.bsl,Ext/SessionModule.bsl" "-Dsonar.exclusions=it's"`},
+		{FormatDotenv, `SONAR_SCANNER_OPTS="-Dsonar.inclusions=Catalogs/Справочник1/**/*.bsl,Ext/SessionModule.bsl -Dsonar.exclusions=it's"`},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(AbsPathTestSrcFolder, "рн_")
+		fndr.Format = testCase.format
+		assert.Equal(t, testCase.expectedLine, fndr.formatScannerArgs(args), testCase.format)
+	}
+}
+
+func TestNeedsResponseFile(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_")
+	fndr.Format = FormatArgs
+	fndr.MaxArgsLength = 10
+	assert.False(t, fndr.needsResponseFile([]string{"-Da=b"}, "'-Da=b'"))
+	assert.True(t, fndr.needsResponseFile([]string{"-Da=bcdefgh"}, "'-Da=bcdefgh'"))
+
+	// without limit arguments are never written to response file
+	fndr.MaxArgsLength = 0
+	assert.False(t, fndr.needsResponseFile([]string{"-Da=bcdefgh"}, "'-Da=bcdefgh'"))
+
+	// options in SONAR_SCANNER_OPTS can't contain spaces
+	assert.False(t, fndr.needsResponseFile([]string{"-Da=b c"}, "'-Da=b c'"))
+	fndr.Format = FormatDotenv
+	assert.True(t, fndr.needsResponseFile([]string{"-Da=b c"}, `SONAR_SCANNER_OPTS="-Da=b c"`))
+
+	// variables are expanded by cmd.exe in double quotes
+	assert.False(t, fndr.needsResponseFile([]string{"-Da=100%PATH%"}, `SONAR_SCANNER_OPTS="-Da=100%PATH%"`))
+	fndr.Format = FormatCmd
+	assert.True(t, fndr.needsResponseFile([]string{"-Da=100%PATH%"}, `"-Da=100%PATH%"`))
+	assert.True(t, fndr.needsResponseFile([]string{"-Da=!PATH!"}, `"-Da=!PATH!"`))
+	assert.False(t, fndr.needsResponseFile([]string{"-Da=b c"}, `"-Da=b c"`))
+}
+
+func TestWriteScannerArgsToSTDOUT(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	testTable := []struct {
+		format       string
+		prefix       string
+		expectedLine string
+	}{
+		{FormatCmd, `C:\src\cf`, `"-Dsonar.inclusions=C:\src\cf/`},
+		{FormatCmd, `C:\src\100%PATH%\cf`, `"-Dproject.settings=`},
+		{FormatArgs, `C:\src\100%PATH%\cf`, `'-Dsonar.inclusions=C:\src\100%PATH%\cf/`},
+	}
+
+	for _, testCase := range testTable {
+
+		output, err := os.Create(path.Join(tempDir, "stdout"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		os.Stdout = output
+
+		fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер")
+		fndr.Format = testCase.format
+		fndr.Prefix = testCase.prefix
+		fndr.ResponseFile = path.Join(tempDir, "sonar-args.properties")
+		fndr.writeScannerArgsToSTDOUT()
+		output.Close()
+
+		data, err := ioutil.ReadFile(output.Name())
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), testCase.expectedLine), string(data))
+	}
+}
+
+func TestWriteScannerArgs(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_")
+	fndr.Exclusions = true
+	properties := fndr.getScannerProperties()
+	if assert.Equal(t, 2, len(properties)) {
+		assert.Equal(t, "sonar.inclusions", properties[0].Key)
+		assert.Equal(t, fndr.getAllBslFilesPaths(), properties[0].Paths)
+		assert.Equal(t, exclusionsKey, properties[1].Key)
+		assert.Equal(t, fndr.getAllExcludedFilesPaths(), properties[1].Paths)
+	}
+
+	args := scannerArgs(properties)
+	assert.Equal(t, "-Dsonar.inclusions="+strings.Join(properties[0].Paths, ","), args[0])
+
+	// response file is a properties file with unicode symbols
+	fndr.ResponseFile = path.Join(tempDir, "sonar-args.properties")
+	assert.NoError(t, fndr.writeResponseFile(properties))
+	data, err := ioutil.ReadFile(fndr.ResponseFile)
+	assert.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "sonar.inclusions="+fndr.stringToUnicode(properties[0].Paths[0])+", \\\n"))
+	assert.Contains(t, content, "\nsonar.exclusions=")
+	assert.NotContains(t, content, "рн_")
+}
+
+func TestScannerFormatsAreMachineReadable(t *testing.T) {
+	for _, format := range []string{FormatArgs, FormatCmd, FormatDotenv} {
+		assert.True(t, IsMachineReadable(format), format)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/thoas/go-funk"
+)
+
+// scopeConfiguration is a name of object and type for modules of configuration
+const scopeConfiguration = "Configuration"
+
+// ScopeModule is a module of resolved scope with its metadata object and subsystems
+type ScopeModule struct {
+	Path       string   `json:"path"`
+	Object     string   `json:"object"`
+	Type       string   `json:"type"`
+	Kind       string   `json:"kind,omitempty"`
+	Language   string   `json:"language"`
+	Subsystems []string `json:"subsystems"`
+}
+
+// ScopeSummary is a summary counts of modules of resolved scope
+type ScopeSummary struct {
+	Modules    int            `json:"modules"`
+	Objects    int            `json:"objects"`
+	Subsystems int            `json:"subsystems"`
+	Types      map[string]int `json:"types"`
+	Kinds      map[string]int `json:"kinds"`
+	Languages  map[string]int `json:"languages"`
+}
+
+// ScopeReport is a resolved scope of modules with summary
+type ScopeReport struct {
+	Modules []ScopeModule `json:"modules"`
+	Summary ScopeSummary  `json:"summary"`
+}
+
+// newScopeModule returns module of scope by output path to its file
+func newScopeModule(filePath string, object string, typeName string, subsystems []string) ScopeModule {
+
+	sm := ScopeModule{
+		Path:       filePath,
+		Object:     object,
+		Type:       typeName,
+		Language:   moduleLanguage(filePath),
+		Subsystems: subsystems,
+	}
+	if kind, ok := classifyModule(filePath); ok {
+		sm.Kind = string(kind)
+	}
+	if sm.Subsystems == nil {
+		sm.Subsystems = []string{}
+	}
+
+	return sm
+}
+
+// getScopeModules returns modules of resolved scope of source root with their objects and subsystems
+func (f *Finder) getScopeModules() []ScopeModule {
+
+	var modules []ScopeModule
+
+	// modules of configuration are first
+	for _, filePath := range f.getConfigurationModulesFilesPaths() {
+		modules = append(modules, newScopeModule(filePath, scopeConfiguration, scopeConfiguration, nil))
+	}
+
+	// matched subsystems are collected while objects of scope are selected
+	metadataNames := f.getSliceMetadataName()
+	subsystemsByName := f.subsystemsByName
+
+	for _, name := range metadataNames {
+
+		object := normalizeMetadataName(name)
+		typeName, _, _ := splitMetadataName(object)
+
+		subsystems := funk.UniqString(subsystemsByName[object])
+		sort.Strings(subsystems)
+
+		for _, filePath := range f.getObjectBslFilesPaths(name) {
+			modules = append(modules, newScopeModule(filePath, object, typeName, subsystems))
+		}
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Количество bsl модулей для проверки: %d", len(modules))
+	}
+
+	for idx := range modules {
+		modules[idx].Path = f.prefixPaths([]string{modules[idx].Path})[0]
+	}
+
+	return modules
+}
+
+// getScopeReport returns modules of main and all additional source roots with summary counts
+func (f *Finder) getScopeReport() ScopeReport {
+
+	modules := f.getScopeModules()
+
+	for _, r := range f.Roots {
+		if f.Logging {
+			f.Logger.Printf(">>> Корень исходных файлов: %s", r.Srcdir)
+		}
+		rf := f.rootFinder(r)
+		rf.checkPlatformVersion()
+		modules = append(modules, rf.getScopeModules()...)
+	}
+
+	// the same order of modules as in list of paths
+	modulesByPath := make(map[string]ScopeModule)
+	var filesPaths []string
+	for _, sm := range modules {
+		if _, ok := modulesByPath[sm.Path]; ok {
+			continue
+		}
+		modulesByPath[sm.Path] = sm
+		filesPaths = append(filesPaths, sm.Path)
+	}
+
+	report := ScopeReport{
+		Modules: []ScopeModule{},
+		Summary: ScopeSummary{
+			Types:     make(map[string]int),
+			Kinds:     make(map[string]int),
+			Languages: make(map[string]int),
+		},
+	}
+
+	objects := make(map[string]bool)
+	subsystems := make(map[string]bool)
+
+	for _, filePath := range f.groupByLanguage(filesPaths) {
+
+		sm := modulesByPath[filePath]
+		report.Modules = append(report.Modules, sm)
+
+		objects[sm.Object] = true
+		for _, chain := range sm.Subsystems {
+			subsystems[chain] = true
+		}
+		report.Summary.Types[sm.Type]++
+		if len(sm.Kind) != 0 {
+			report.Summary.Kinds[sm.Kind]++
+		}
+		report.Summary.Languages[sm.Language]++
+	}
+
+	report.Summary.Modules = len(report.Modules)
+	report.Summary.Objects = len(objects)
+	report.Summary.Subsystems = len(subsystems)
+
+	return report
+}
+
+// writeScopeToSTDOUT prints resolved scope in json format
+func (f *Finder) writeScopeToSTDOUT() {
+
+	out, err := json.MarshalIndent(f.getScopeReport(), "", "  ")
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	fmt.Println(string(out))
+}
@@ -0,0 +1,84 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScopeReport(t *testing.T) {
+
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер пс_")
+	fndr.ConfigModules = []string{string(SessionModule)}
+	expectedPaths := fndr.getAllBslFilesPaths()
+	report := fndr.getScopeReport()
+
+	// modules are in the same order as list of paths
+	var filesPaths []string
+	for _, sm := range report.Modules {
+		filesPaths = append(filesPaths, sm.Path)
+	}
+	assert.Equal(t, expectedPaths, filesPaths)
+	assert.Equal(t, len(filesPaths), report.Summary.Modules)
+
+	assert.Equal(t, ScopeModule{
+		Path:       "Ext/SessionModule.bsl",
+		Object:     "Configuration",
+		Type:       "Configuration",
+		Kind:       string(SessionModule),
+		Language:   LanguageBSL,
+		Subsystems: []string{},
+	}, report.Modules[0])
+
+	for _, sm := range report.Modules {
+		if sm.Path != "DataProcessors/Обработка10/Ext/ManagerModule.bsl" {
+			continue
+		}
+		assert.Equal(t, "DataProcessor.Обработка10", sm.Object)
+		assert.Equal(t, "DataProcessor", sm.Type)
+		assert.Equal(t, string(ManagerModule), sm.Kind)
+		assert.Equal(t, []string{"пс_Доп/пс_поддоп", "рн_Супер"}, sm.Subsystems)
+	}
+
+	assert.Equal(t, 1, report.Summary.Types["Configuration"])
+	assert.Equal(t, 1, report.Summary.Kinds[string(SessionModule)])
+	assert.Equal(t, report.Summary.Modules, report.Summary.Languages[LanguageBSL])
+	assert.True(t, report.Summary.Subsystems >= 2)
+
+	// objects of names scope and list have no subsystems
+	fndr = NewFinder(AbsPathTestSrcFolder, "Обработка10")
+	fndr.Scope = ScopeNames
+	report = fndr.getScopeReport()
+	if assert.NotEmpty(t, report.Modules) {
+		assert.Equal(t, "DataProcessor.Обработка10", report.Modules[0].Object)
+		assert.Empty(t, report.Modules[0].Subsystems)
+	}
+	assert.Equal(t, 0, report.Summary.Subsystems)
+
+	// empty scope
+	report = NewFinder(AbsPathTestSrcFolder, "нет_").getScopeReport()
+	assert.NotNil(t, report.Modules)
+	assert.Equal(t, 0, report.Summary.Modules)
+}
+
+func TestSubsystemsByName(t *testing.T) {
+
+	// subsystems of objects are collected while objects of scope are selected
+	fndr := NewFinder(AbsPathTestSrcFolder, "рн_Супер пс_")
+	metadataNames := fndr.getSliceMetadataName()
+	assert.ElementsMatch(t, []string{"пс_Доп/пс_поддоп", "рн_Супер"}, fndr.subsystemsByName["DataProcessor.Обработка10"])
+	for _, name := range metadataNames {
+		if len(fndr.subsystemsByName[normalizeMetadataName(name)]) == 0 {
+			assert.Fail(t, "object without subsystems", name)
+		}
+	}
+
+	// selection is repeated without duplicates
+	fndr.getSliceMetadataName()
+	assert.ElementsMatch(t, []string{"пс_Доп/пс_поддоп", "рн_Супер"}, fndr.subsystemsByName["DataProcessor.Обработка10"])
+}
+
+func TestIsMachineReadable(t *testing.T) {
+	assert.False(t, IsMachineReadable(FormatText))
+	assert.True(t, IsMachineReadable(FormatJSON))
+}
@@ -0,0 +1,377 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Modes of matching parse phrases with subsystems
+const (
+	MatchPrefix = "prefix" // file name of subsystem starts with phrase
+	MatchGlob   = "glob"   // name or synonym of subsystem matches glob pattern
+	MatchRegexp = "regexp" // name or synonym of subsystem matches regular expression
+)
+
+// subsystem is a structure for unmarshal subsystem xml file
+type subsystem struct {
+	Name            string   `xml:"Subsystem>Properties>Name"`
+	ObjectBelonging string   `xml:"Subsystem>Properties>ObjectBelonging"`
+	Synonyms        []string `xml:"Subsystem>Properties>Synonym>item>content"`
+	Content         []string `xml:"Subsystem>Properties>Content>Item"`
+	Children        []string `xml:"Subsystem>ChildObjects>Subsystem"`
+}
+
+// edtSubsystem is a structure for unmarshal subsystem mdo file of 1C:EDT project
+type edtSubsystem struct {
+	Name            string   `xml:"name"`
+	ObjectBelonging string   `xml:"objectBelonging"`
+	Synonyms        []string `xml:"synonym>value"`
+	Content         []string `xml:"content"`
+	Children        []string `xml:"subsystems"`
+}
+
+// parsedSubsystem returns subsystem of file read once per run, subsystems are parsed
+// by selection of objects and again by modules of project and other reports
+func (f *Finder) parsedSubsystem(filename string) (*subsystem, error) {
+
+	if s, ok := f.subsystemsByPath[filename]; ok {
+		return s, nil
+	}
+
+	s, err := readSubsystem(f.files, filename)
+	if err != nil {
+		return nil, err
+	}
+	if f.subsystemsByPath == nil {
+		f.subsystemsByPath = make(map[string]*subsystem)
+	}
+	f.subsystemsByPath[filename] = s
+
+	return s, nil
+}
+
+// readSubsystem reads and unmarshal subsystem xml or mdo file
+func readSubsystem(files fileSystem, filename string) (*subsystem, error) {
+
+	byteValue, err := files.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if path.Ext(filename) == ".mdo" {
+		es := &edtSubsystem{}
+		err = xml.Unmarshal(byteValue, es)
+		if err != nil {
+			return nil, err
+		}
+		return &subsystem{
+			Name:            es.Name,
+			ObjectBelonging: es.ObjectBelonging,
+			Synonyms:        es.Synonyms,
+			Content:         es.Content,
+			Children:        es.Children,
+		}, nil
+	}
+
+	s := &subsystem{}
+	err = xml.Unmarshal(byteValue, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// subsystemNames returns names of parents of subsystem and of itself by path to its file
+func (f *Finder) subsystemNames(filename string) []string {
+
+	relPath, err := filepath.Rel(f.rootSubsystemsPath, filename)
+	if err != nil {
+		return []string{strings.TrimSuffix(path.Base(filename), path.Ext(filename))}
+	}
+
+	// path looks like "A/Subsystems/B.xml" or "A/Subsystems/B/B.mdo"
+	parts := strings.Split(filepath.ToSlash(strings.TrimSuffix(relPath, path.Ext(relPath))), "/")
+	if f.Layout == LayoutEDT {
+		parts = parts[:len(parts)-1]
+	}
+
+	var names []string
+	for idx := 0; idx < len(parts); idx += 2 {
+		names = append(names, parts[idx])
+	}
+
+	return names
+}
+
+// childSubsystemFilePath returns path to file of child subsystem
+func (f *Finder) childSubsystemFilePath(filename string, child string) string {
+	return f.subsystemFilePath(append(f.subsystemNames(filename), child))
+}
+
+// isDescendantSubsystem checks that subsystem is a descendant of parent subsystem
+func (f *Finder) isDescendantSubsystem(filename string, parentFilename string) bool {
+
+	names := f.subsystemNames(filename)
+	parentNames := f.subsystemNames(parentFilename)
+
+	if len(names) <= len(parentNames) {
+		return false
+	}
+	for idx, name := range parentNames {
+		if names[idx] != name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getChildSubsystemsFilesPaths returns paths to xml files of all descendants of subsystem
+func (f *Finder) getChildSubsystemsFilesPaths(filename string) []string {
+
+	var childFilesPaths []string
+
+	s, err := f.parsedSubsystem(filename)
+	if err != nil {
+		println(err.Error())
+		return []string{}
+	}
+
+	for _, child := range s.Children {
+		childFilePath := f.childSubsystemFilePath(filename, child)
+		childFilesPaths = append(childFilesPaths, childFilePath)
+		childFilesPaths = append(childFilesPaths, f.getChildSubsystemsFilesPaths(childFilePath)...)
+	}
+
+	return childFilesPaths
+}
+
+// splitPhrases splits parse phrases to including and excluding (started with "!") phrases
+func splitPhrases(phrases string) (include []string, exclude []string) {
+	for _, phrase := range strings.Fields(phrases) {
+		if strings.HasPrefix(phrase, "!") {
+			if len(phrase) > 1 {
+				exclude = append(exclude, phrase[1:])
+			}
+			continue
+		}
+		include = append(include, phrase)
+	}
+	return include, exclude
+}
+
+// splitSelectors splits parse phrases to hierarchical paths and other phrases
+func splitSelectors(phrases []string) (selectors []string, others []string) {
+	for _, phrase := range phrases {
+		if isPathSelector(phrase) {
+			selectors = append(selectors, phrase)
+			continue
+		}
+		others = append(others, phrase)
+	}
+	return selectors, others
+}
+
+// isPathSelector checks that phrase is a hierarchical path to subsystem like "Parent/Child" or "Parent/**"
+func isPathSelector(phrase string) bool {
+	return strings.Contains(phrase, "/")
+}
+
+// resolvePathSelector returns path to file of subsystem by hierarchical path
+// and flag that all descendants of the subsystem are selected too
+func (f *Finder) resolvePathSelector(phrase string) (filename string, withDescendants bool) {
+
+	selector := strings.Trim(phrase, "/")
+	if selector == "**" || strings.HasSuffix(selector, "/**") {
+		withDescendants = true
+		selector = strings.Trim(strings.TrimSuffix(selector, "**"), "/")
+	}
+
+	return f.subsystemFilePath(strings.Split(selector, "/")), withDescendants
+}
+
+// getSelectedSubsystemsFilesPaths returns paths to xml files of subsystems selected by hierarchical paths
+func (f *Finder) getSelectedSubsystemsFilesPaths(selectors []string) []string {
+
+	var subsystemsFilesPaths []string
+
+	for _, selector := range selectors {
+
+		filename, withDescendants := f.resolvePathSelector(selector)
+
+		// check file exist
+		if !exists(f.files, filename) {
+			if f.Logging {
+				f.Logger.Printf("Подсистема не найдена: %s", selector)
+			}
+			continue
+		}
+
+		subsystemsFilesPaths = append(subsystemsFilesPaths, filename)
+		if withDescendants {
+			subsystemsFilesPaths = append(subsystemsFilesPaths, f.getChildSubsystemsFilesPaths(filename)...)
+		}
+	}
+
+	return subsystemsFilesPaths
+}
+
+// matchPhrase checks value of subsystem name or synonym with phrase in the matching mode
+func matchPhrase(mode string, phrase string, value string) bool {
+	switch mode {
+	case MatchGlob:
+		matched, _ := path.Match(phrase, value)
+		return matched
+	case MatchRegexp:
+		matched, _ := regexp.MatchString(phrase, value)
+		return matched
+	default:
+		return strings.HasPrefix(value, phrase)
+	}
+}
+
+// matchAnyPhrase checks value with all phrases in the matching mode
+func matchAnyPhrase(mode string, phrases []string, value string) bool {
+	for _, phrase := range phrases {
+		if matchPhrase(mode, phrase, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubsystemMatched checks that subsystem matches one of phrases
+func (f *Finder) isSubsystemMatched(filename string, phrases []string) bool {
+
+	// in prefix mode only file name is checked
+	values := []string{strings.TrimSuffix(path.Base(filename), path.Ext(filename))}
+
+	if f.Match == MatchGlob || f.Match == MatchRegexp {
+		s, err := f.parsedSubsystem(filename)
+		if err != nil {
+			println(err.Error())
+			return false
+		}
+		values = append([]string{s.Name}, s.Synonyms...)
+	}
+
+	for _, phrase := range phrases {
+		if isPathSelector(phrase) {
+			selected, withDescendants := f.resolvePathSelector(phrase)
+			if filename == selected || (withDescendants && f.isDescendantSubsystem(filename, selected)) {
+				return true
+			}
+			continue
+		}
+		for _, value := range values {
+			if matchPhrase(f.Match, phrase, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getAllSubsystemsFilesPaths returns paths to files of all subsystems of configuration
+func (f *Finder) getAllSubsystemsFilesPaths() []string {
+
+	var subsystemsFilesPaths []string
+
+	err := f.files.Walk(f.rootSubsystemsPath, func(wpath string, info fs.FileInfo, err error) error {
+		if info == nil || !info.IsDir() {
+			return nil
+		}
+		if f.Layout == LayoutEDT {
+			// folder of subsystem contains file with the same name
+			sFile := path.Join(wpath, info.Name()+f.subsystemExt())
+			if exists(f.files, sFile) {
+				subsystemsFilesPaths = append(subsystemsFilesPaths, sFile)
+			}
+			return nil
+		}
+		sFiles, _ := f.files.Glob(path.Join(wpath, "*"+f.subsystemExt()))
+		subsystemsFilesPaths = append(subsystemsFilesPaths, sFiles...)
+
+		return nil
+	})
+	if err != nil {
+		println(err.Error())
+		return []string{}
+	}
+
+	return subsystemsFilesPaths
+}
+
+// getMatchedSubsystemsFilesPaths returns paths to xml files of all subsystems which name or synonym matches phrases
+func (f *Finder) getMatchedSubsystemsFilesPaths(phrases []string) []string {
+
+	var subsystemsFilesPaths []string
+
+	for _, sFile := range f.getAllSubsystemsFilesPaths() {
+		if f.isSubsystemMatched(sFile, phrases) {
+			subsystemsFilesPaths = append(subsystemsFilesPaths, sFile)
+		}
+	}
+
+	return subsystemsFilesPaths
+}
+
+// excludeSubsystems removes subsystems matched excluding phrases (and optionally their descendants) from slice
+func (f *Finder) excludeSubsystems(subsystemsFilesPaths []string, exclude []string) []string {
+
+	if len(exclude) == 0 {
+		return subsystemsFilesPaths
+	}
+
+	var filteredPaths []string
+
+	for _, sPath := range subsystemsFilesPaths {
+
+		excluded := f.isSubsystemMatched(sPath, exclude)
+
+		// check all parent subsystems
+		if !excluded && f.ExcludeChildren {
+			names := f.subsystemNames(sPath)
+			for idx := 1; idx < len(names); idx++ {
+				if f.isSubsystemMatched(f.subsystemFilePath(names[:idx]), exclude) {
+					excluded = true
+					break
+				}
+			}
+		}
+
+		if excluded {
+			if f.Logging {
+				f.Logger.Printf("Подсистема исключена из анализа: %s", sPath)
+			}
+			continue
+		}
+
+		filteredPaths = append(filteredPaths, sPath)
+	}
+
+	return filteredPaths
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2021 ALEKSEY MAKSIMKIN <maximkin@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package finder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// dumpFormatPlain is a value of attribute format of ConfigDumpInfo.xml for plain dump
+const dumpFormatPlain = "Plain"
+
+// minFormatVersion is a version of dump format of platform 8.3.10
+const minFormatVersion = "2.4"
+
+// minCompatibilityMode is a minimal supported compatibility mode of configuration
+const minCompatibilityMode = "8.3.10"
+
+// parseVersion converts version like "2.6", "8.3.12" or "Version8_3_12" to numbers
+func parseVersion(version string) ([]int, bool) {
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "Version")
+	if len(version) == 0 {
+		return nil, false
+	}
+
+	var numbers []int
+	for _, part := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' }) {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, len(numbers) != 0
+}
+
+// compareVersions returns -1, 0 or 1 if version a is less, equal or greater than version b
+func compareVersions(a []int, b []int) int {
+	for idx := 0; idx < len(a) || idx < len(b); idx++ {
+		var numA, numB int
+		if idx < len(a) {
+			numA = a[idx]
+		}
+		if idx < len(b) {
+			numB = b[idx]
+		}
+		if numA < numB {
+			return -1
+		}
+		if numA > numB {
+			return 1
+		}
+	}
+	return 0
+}
+
+// isVersionLess checks that version is less than minimal version, unknown versions are not checked
+func isVersionLess(version string, minVersion string) bool {
+	numbers, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	minNumbers, _ := parseVersion(minVersion)
+	return compareVersions(numbers, minNumbers) < 0
+}
+
+// CheckSources is the method for check that folder contains sources of configuration in supported format
+func (f *Finder) CheckSources() error {
+
+	if f.filesErr != nil {
+		return fmt.Errorf("Can't read archive \"%s\": %s", f.srcdir, f.filesErr)
+	}
+
+	switch f.Layout {
+	case LayoutFlat:
+		return fmt.Errorf("Path \"%s\" contains dump in plain format, which is not supported: dump configuration in hierarchical format", f.srcdir)
+	case LayoutUnknown:
+		// path to 1C:EDT project instead of its src folder
+		if _, err := os.Stat(path.Join(f.srcdir, "src", "Configuration", "Configuration.mdo")); err == nil {
+			return fmt.Errorf("Path \"%s\" is 1C:EDT project, use path to its \"src\" folder", f.srcdir)
+		}
+		return fmt.Errorf("Path \"%s\" doesn't contain Configuration.xml, Configuration/Configuration.mdo "+
+			"or dumps of external data processors and reports", f.srcdir)
+	}
+
+	return nil
+}
+
+// checkPlatformVersion logs version of dump format and compatibility mode and warns about unsupported versions
+func (f *Finder) checkPlatformVersion() {
+
+	if f.Layout != LayoutDesigner && f.Layout != LayoutEDT {
+		return
+	}
+
+	c, err := readConfiguration(f.files, f.configurationFilePath())
+	if err != nil {
+		return
+	}
+
+	// version of dump format is placed in Configuration.xml and ConfigDumpInfo.xml
+	formatVersion := c.FormatVersion
+	if di, err := readDumpInfo(f.files, path.Join(f.srcdir, "ConfigDumpInfo.xml")); err == nil && len(di.Version) != 0 {
+		formatVersion = di.Version
+	}
+
+	compatibilityMode := c.CompatibilityMode
+	if c.ObjectBelonging == objectBelongingAdopted {
+		compatibilityMode = c.ExtensionCompatibilityMode
+	}
+
+	if f.Logging {
+		f.Logger.Printf(">>> Формат исходных файлов: %s", f.Layout)
+		if len(formatVersion) != 0 {
+			f.Logger.Printf(">>> Версия формата выгрузки: %s", formatVersion)
+		}
+		if len(compatibilityMode) != 0 {
+			f.Logger.Printf(">>> Режим совместимости: %s", compatibilityMode)
+		}
+	}
+
+	if isVersionLess(formatVersion, minFormatVersion) {
+		fmt.Fprintf(os.Stderr, "WARN\tВерсия формата выгрузки %s ниже %s: выгрузка сделана платформой старше 8.3.10 и может быть прочитана неверно\n",
+			formatVersion, minFormatVersion)
+	}
+
+	if isVersionLess(compatibilityMode, minCompatibilityMode) {
+		fmt.Fprintf(os.Stderr, "WARN\tРежим совместимости %s ниже %s: анализ таких конфигураций не поддерживается\n",
+			compatibilityMode, minCompatibilityMode)
+	}
+}
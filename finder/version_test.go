@@ -0,0 +1,118 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	testTable := []struct {
+		version         string
+		expectedNumbers []int
+		expectedOk      bool
+	}{
+		{"2.6", []int{2, 6}, true},
+		{"8.3.12", []int{8, 3, 12}, true},
+		{"Version8_3_12", []int{8, 3, 12}, true},
+		{"DontUse", nil, false},
+		{"", nil, false},
+	}
+
+	for _, testCase := range testTable {
+		numbers, ok := parseVersion(testCase.version)
+		assert.Equal(t, testCase.expectedOk, ok, testCase.version)
+		assert.Equal(t, testCase.expectedNumbers, numbers, testCase.version)
+	}
+}
+
+func TestIsVersionLess(t *testing.T) {
+	testTable := []struct {
+		version      string
+		minVersion   string
+		expectedLess bool
+	}{
+		{"2.3", minFormatVersion, true},
+		{"2.4", minFormatVersion, false},
+		{"2.10", minFormatVersion, false},
+		{"Version8_3_8", minCompatibilityMode, true},
+		{"Version8_2_16", minCompatibilityMode, true},
+		{"Version8_3_10", minCompatibilityMode, false},
+		{"8.3.12", minCompatibilityMode, false},
+		{"8.3", minCompatibilityMode, true},
+		{"DontUse", minCompatibilityMode, false},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expectedLess, isVersionLess(testCase.version, testCase.minVersion), testCase.version)
+	}
+}
+
+func TestCheckSources(t *testing.T) {
+
+	tempDir, err := ioutil.TempDir("", "bsl2sonar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// project of 1C:EDT instead of its src folder
+	edtProject := path.Join(tempDir, "edt")
+	assert.NoError(t, os.MkdirAll(path.Join(edtProject, "src", "Configuration"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(path.Join(edtProject, "src", "Configuration", "Configuration.mdo"), []byte{}, os.ModePerm))
+
+	// plain dump of Designer
+	flatDump := path.Join(tempDir, "flat")
+	assert.NoError(t, os.MkdirAll(flatDump, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(path.Join(flatDump, "Configuration.xml"), []byte{}, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(path.Join(flatDump, "Catalog.Справочник1.xml"), []byte{}, os.ModePerm))
+
+	testTable := []struct {
+		srcdir         string
+		expectedLayout string
+		expectedError  string
+	}{
+		{AbsPathTestSrcFolder, LayoutDesigner, ""},
+		{AbsPathTestEDTFolder, LayoutEDT, ""},
+		{AbsPathTestExtensionFolder, LayoutDesigner, ""},
+		{AbsPathTestExternalFolder, LayoutExternal, ""},
+		{flatDump, LayoutFlat, "plain format"},
+		{edtProject, LayoutUnknown, "use path to its \"src\" folder"},
+		{tempDir, LayoutUnknown, "doesn't contain Configuration.xml"},
+	}
+
+	for _, testCase := range testTable {
+		fndr := NewFinder(testCase.srcdir, "")
+		assert.Equal(t, testCase.expectedLayout, fndr.Layout, testCase.srcdir)
+		err := fndr.CheckSources()
+		if len(testCase.expectedError) == 0 {
+			assert.NoError(t, err, testCase.srcdir)
+			continue
+		}
+		if assert.Error(t, err, testCase.srcdir) {
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		}
+	}
+}
+
+func TestReadVersions(t *testing.T) {
+
+	c, err := readConfiguration(osFiles, path.Join(AbsPathTestSrcFolder, "Configuration.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2.6", c.FormatVersion)
+	assert.Equal(t, "Version8_3_12", c.CompatibilityMode)
+
+	c, err = readConfiguration(osFiles, path.Join(AbsPathTestEDTFolder, "Configuration/Configuration.mdo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "8.3.12", c.CompatibilityMode)
+
+	c, err = readConfiguration(osFiles, path.Join(AbsPathTestExtensionFolder, "Configuration.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Version8_3_14", c.ExtensionCompatibilityMode)
+
+	di, err := readDumpInfo(osFiles, path.Join(AbsPathTestSrcFolder, "ConfigDumpInfo.xml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hierarchical", di.Format)
+	assert.Equal(t, "2.6", di.Version)
+}
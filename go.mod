@@ -4,8 +4,8 @@ go 1.16
 
 require (
 	github.com/spf13/cobra v1.1.3
-	github.com/stretchr/testify v1.7.0 // indirect
-	github.com/thoas/go-funk v0.8.0 // indirect
+	github.com/stretchr/testify v1.7.0
+	github.com/thoas/go-funk v0.8.0
 	golang.org/x/lint v0.0.0-20201208152925-83fdc39ff7b5 // indirect
 	golang.org/x/tools v0.1.0 // indirect
 )